@@ -1,14 +1,86 @@
 package closer
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
 
 type Client interface {
 	Close() error
 }
 
+// errorHandler holds the handler Close reports errors to.
+var errorHandler atomic.Pointer[func(error)]
+
+// printError is the default error handler. It prints given error to stdout.
+func printError(err error) {
+	fmt.Printf("%+v", err)
+}
+
+// SetErrorHandler replaces the handler that Close reports close errors to and returns the previous one.
+// Give nil to restore the default handler that prints errors to stdout.
+func SetErrorHandler(h func(error)) func(error) {
+	if h == nil {
+		h = printError
+	}
+	prev := errorHandler.Swap(&h)
+	if prev == nil {
+		return printError
+	}
+	return *prev
+}
+
+// handleError passes given error to the current error handler.
+func handleError(err error) {
+	if h := errorHandler.Load(); h != nil {
+		(*h)(err)
+		return
+	}
+	printError(err)
+}
+
 // Close call specific client's close method. It is intended to be used with defer.
+// Close error is reported to the handler set by SetErrorHandler.
 func Close(cl Client) {
 	if err := cl.Close(); err != nil {
-		fmt.Printf("%+v", err)
+		handleError(err)
+	}
+}
+
+// CloseWith call specific client's close method and passes close error to given handler.
+// When nil is given for handler, the handler set by SetErrorHandler is used.
+func CloseWith(cl Client, handler func(error)) {
+	if err := cl.Close(); err != nil {
+		if handler == nil {
+			handleError(err)
+			return
+		}
+		handler(err)
+	}
+}
+
+// CloseInto call specific client's close method and merges close error into the error pointed by errp.
+// It is intended to be used with defer and a named return value:
+//
+//	func write() (err error) {
+//		f, err := os.Create(name)
+//		...
+//		defer closer.CloseInto(f, &err)
+//		...
+//	}
+func CloseInto(cl Client, errp *error) {
+	err := cl.Close()
+	if err == nil {
+		return
+	}
+	if errp == nil {
+		handleError(err)
+		return
+	}
+	if *errp == nil {
+		*errp = err
+		return
 	}
+	*errp = errors.Join(*errp, err)
 }
@@ -1,6 +1,7 @@
 package closer_test
 
 import (
+	"errors"
 	"os"
 	"testing"
 
@@ -8,8 +9,63 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+var errClose = errors.New("close failed")
+
+type errClient struct {
+	err    error
+	closed int
+}
+
+func (c *errClient) Close() error {
+	c.closed++
+	return c.err
+}
+
 func TestClose(t *testing.T) {
 	f, err := os.Open("./closer_test.go")
 	assert.NoError(t, err)
 	closer.Close(f)
 }
+
+func TestSetErrorHandler(t *testing.T) {
+	var got []error
+	prev := closer.SetErrorHandler(func(err error) { got = append(got, err) })
+	defer closer.SetErrorHandler(prev)
+
+	closer.Close(&errClient{err: errClose})
+	closer.Close(&errClient{})
+	assert.EqualValues(t, []error{errClose}, got)
+
+	// nil restores the default handler.
+	h := closer.SetErrorHandler(nil)
+	assert.NotNil(t, h)
+	assert.NotNil(t, closer.SetErrorHandler(h))
+}
+
+func TestCloseWith(t *testing.T) {
+	var got error
+	c := &errClient{err: errClose}
+	closer.CloseWith(c, func(err error) { got = err })
+	assert.EqualValues(t, 1, c.closed)
+	assert.True(t, errors.Is(got, errClose))
+
+	got = nil
+	closer.CloseWith(&errClient{}, func(err error) { got = err })
+	assert.NoError(t, got)
+}
+
+func TestCloseInto(t *testing.T) {
+	f := func(c closer.Client, ret error) (err error) {
+		defer closer.CloseInto(c, &err)
+		return ret
+	}
+
+	assert.NoError(t, f(&errClient{}, nil))
+	assert.True(t, errors.Is(f(&errClient{err: errClose}, nil), errClose))
+	assert.EqualError(t, f(&errClient{}, errors.New("body")), "body")
+
+	body := errors.New("body")
+	err := f(&errClient{err: errClose}, body)
+	assert.True(t, errors.Is(err, body))
+	assert.True(t, errors.Is(err, errClose))
+}
@@ -0,0 +1,55 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ContextCloser is a client that can close within the deadline of given context.
+type ContextCloser interface {
+	Close(ctx context.Context) error
+}
+
+// ErrNotCloser is returned when given value is neither Client nor ContextCloser.
+var ErrNotCloser = errors.New("not a closer")
+
+// CloseContext closes given Client or ContextCloser and returns ctx.Err() when ctx is done before closing ends.
+// ContextCloser receives ctx as is and is trusted to honor it, even when ctx is already done. Client is closed in
+// another goroutine, which keeps running in background when ctx is done first since Client has no way to be
+// cancelled. Closing is always started, so that nothing is left open even after the deadline.
+func CloseContext(ctx context.Context, cl interface{}) error {
+	var c Client
+	switch v := cl.(type) {
+	case ContextCloser:
+		return v.Close(ctx)
+	case Client:
+		c = v
+	default:
+		return fmt.Errorf("%w: %T", ErrNotCloser, cl)
+	}
+
+	done := make(chan error, 1) // buffered not to leak the goroutine when ctx is done first.
+	go func() {
+		done <- c.Close()
+	}()
+
+	if err := ctx.Err(); err != nil {
+		return err // already done, Close goes on in background.
+	}
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// CloseTimeout closes given Client or ContextCloser and returns context.DeadlineExceeded when closing
+// does not end within given duration.
+func CloseTimeout(cl interface{}, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return CloseContext(ctx, cl)
+}
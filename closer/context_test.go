@@ -0,0 +1,83 @@
+package closer_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/marrbor/goutil/closer"
+	"github.com/stretchr/testify/assert"
+)
+
+// hangClient is a Client that blocks on Close until release is closed.
+type hangClient struct {
+	release chan struct{}
+}
+
+func (c *hangClient) Close() error {
+	<-c.release
+	return nil
+}
+
+// ctxClient is a ContextCloser that waits for context.
+type ctxClient struct {
+	deadline bool
+}
+
+func (c *ctxClient) Close(ctx context.Context) error {
+	_, c.deadline = ctx.Deadline()
+	<-ctx.Done()
+	return errors.New("stopped by context")
+}
+
+func TestCloseContext(t *testing.T) {
+	assert.NoError(t, closer.CloseContext(context.Background(), &errClient{}))
+	assert.True(t, errors.Is(closer.CloseContext(context.Background(), &errClient{err: errClose}), errClose))
+
+	c := &hangClient{release: make(chan struct{})}
+	defer close(c.release)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.True(t, errors.Is(closer.CloseContext(ctx, c), context.DeadlineExceeded))
+}
+
+func TestCloseContext_ContextCloser(t *testing.T) {
+	c := &ctxClient{}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := closer.CloseContext(ctx, c)
+	assert.Error(t, err)
+	assert.True(t, c.deadline)
+}
+
+func TestCloseContext_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// closing is started in background even though ctx is already done.
+	c := &hangClient{release: make(chan struct{})}
+	assert.True(t, errors.Is(closer.CloseContext(ctx, c), context.Canceled))
+	select {
+	case c.release <- struct{}{}:
+	case <-time.After(time.Second):
+		t.Fatal("Close was not called")
+	}
+
+	cc := &ctxClient{}
+	assert.Error(t, closer.CloseContext(ctx, cc))
+	assert.False(t, cc.deadline)
+}
+
+func TestCloseContext_NotCloser(t *testing.T) {
+	err := closer.CloseContext(context.Background(), "string")
+	assert.True(t, errors.Is(err, closer.ErrNotCloser))
+}
+
+func TestCloseTimeout(t *testing.T) {
+	assert.NoError(t, closer.CloseTimeout(&errClient{}, time.Second))
+
+	c := &hangClient{release: make(chan struct{})}
+	defer close(c.release)
+	assert.True(t, errors.Is(closer.CloseTimeout(c, 10*time.Millisecond), context.DeadlineExceeded))
+}
@@ -0,0 +1,50 @@
+package closer
+
+import (
+	"errors"
+	"io"
+	"sync/atomic"
+)
+
+// defaultDrainLimit is the initial value of drain limit.
+const defaultDrainLimit = 256 << 10
+
+// drainLimit holds max bytes DrainClose reads before closing.
+var drainLimit atomic.Int64
+
+func init() {
+	drainLimit.Store(defaultDrainLimit)
+}
+
+// DrainLimit returns max bytes DrainClose reads and discards before closing (default: 256KiB).
+func DrainLimit() int64 {
+	return drainLimit.Load()
+}
+
+// SetDrainLimit changes max bytes DrainClose reads and discards before closing and returns the previous one.
+func SetDrainLimit(limit int64) int64 {
+	return drainLimit.Swap(limit)
+}
+
+// DrainAndClose reads and discards up to limit bytes from given reader, then closes it.
+// It returns the number of discarded bytes. Draining HTTP response body lets net/http reuse the connection.
+// Bodies longer than limit are closed without reading the rest, which closes the connection.
+func DrainAndClose(rc io.ReadCloser, limit int64) (int64, error) {
+	var n int64
+	var rerr error
+	if limit > 0 {
+		n, rerr = io.Copy(io.Discard, io.LimitReader(rc, limit))
+	}
+	if err := rc.Close(); err != nil {
+		return n, errors.Join(rerr, err)
+	}
+	return n, rerr
+}
+
+// DrainClose reads and discards up to DrainLimit bytes from given reader, then closes it.
+// It is intended to be used with defer like Close.
+func DrainClose(rc io.ReadCloser) {
+	if _, err := DrainAndClose(rc, DrainLimit()); err != nil {
+		handleError(err)
+	}
+}
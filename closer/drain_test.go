@@ -0,0 +1,64 @@
+package closer_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/marrbor/goutil/closer"
+	"github.com/stretchr/testify/assert"
+)
+
+type readCloser struct {
+	io.Reader
+	closed int
+	err    error
+}
+
+func (r *readCloser) Close() error {
+	r.closed++
+	return r.err
+}
+
+func TestDrainAndClose(t *testing.T) {
+	rc := &readCloser{Reader: strings.NewReader("0123456789")}
+	n, err := closer.DrainAndClose(rc, 4)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 4, n)
+	assert.EqualValues(t, 1, rc.closed)
+
+	rc = &readCloser{Reader: strings.NewReader("0123456789")}
+	n, err = closer.DrainAndClose(rc, 100)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 10, n)
+
+	rc = &readCloser{Reader: strings.NewReader("0123456789")}
+	n, err = closer.DrainAndClose(rc, 0)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, n)
+	assert.EqualValues(t, 1, rc.closed)
+
+	rc = &readCloser{Reader: strings.NewReader("0123456789"), err: errClose}
+	_, err = closer.DrainAndClose(rc, 100)
+	assert.True(t, errors.Is(err, errClose))
+}
+
+func TestDrainClose(t *testing.T) {
+	assert.EqualValues(t, 256<<10, closer.DrainLimit())
+	prev := closer.SetDrainLimit(3)
+	defer closer.SetDrainLimit(prev)
+	assert.EqualValues(t, 3, closer.DrainLimit())
+
+	r := strings.NewReader("0123456789")
+	rc := &readCloser{Reader: r}
+	closer.DrainClose(rc)
+	assert.EqualValues(t, 1, rc.closed)
+	assert.EqualValues(t, 7, r.Len())
+
+	var got error
+	h := closer.SetErrorHandler(func(err error) { got = err })
+	defer closer.SetErrorHandler(h)
+	closer.DrainClose(&readCloser{Reader: strings.NewReader(""), err: errClose})
+	assert.True(t, errors.Is(got, errClose))
+}
@@ -0,0 +1,111 @@
+package closer
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// CloseError is an error of a resource closed by Group.
+type CloseError struct {
+	Name string // name of the resource given on registration.
+	Err  error  // error returned by the resource.
+}
+
+// Error returns error string with resource name.
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("close %s: %v", e.Name, e.Err)
+}
+
+// Unwrap returns the error returned by the resource.
+func (e *CloseError) Unwrap() error {
+	return e.Err
+}
+
+// ErrGroupClosed is returned when a resource is registered to already closed Group.
+var ErrGroupClosed = errors.New("closer group already closed")
+
+type (
+	// Group closes registered resources in reverse registration order at once.
+	// The zero value is ready to use. Group itself is a Client.
+	Group struct {
+		mu      sync.Mutex
+		entries []groupEntry
+		closed  bool
+		once    sync.Once
+		err     error
+	}
+
+	groupEntry struct {
+		name  string
+		close func() error
+	}
+)
+
+// add registers given close function. It closes given function immediately when the group is already closed.
+func (g *Group) add(name string, f func() error) error {
+	g.mu.Lock()
+	if !g.closed {
+		g.entries = append(g.entries, groupEntry{name: name, close: f})
+		g.mu.Unlock()
+		return nil
+	}
+	g.mu.Unlock()
+
+	// group is already closed, so release given resource here not to leak it.
+	if err := f(); err != nil {
+		return errors.Join(ErrGroupClosed, &CloseError{Name: name, Err: err})
+	}
+	return ErrGroupClosed
+}
+
+// Add registers given client with name used in error. Empty name is replaced with the type of client.
+// When the group is already closed, the client is closed immediately and ErrGroupClosed is returned.
+func (g *Group) Add(name string, cl Client) error {
+	if name == "" {
+		name = fmt.Sprintf("%T", cl)
+	}
+	return g.add(name, cl.Close)
+}
+
+// AddFunc registers given cleanup function that may fail.
+func (g *Group) AddFunc(name string, f func() error) error {
+	return g.add(name, f)
+}
+
+// AddVoid registers given cleanup function that never fails.
+func (g *Group) AddVoid(name string, f func()) error {
+	return g.add(name, func() error {
+		f()
+		return nil
+	})
+}
+
+// Len returns the number of registered resources that are not closed yet.
+func (g *Group) Len() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.entries)
+}
+
+// Close closes all registered resources in reverse registration order. Resources are closed only once even if
+// Close is called several times or from several goroutines; every call returns the same joined error that
+// consists of CloseError for each failed resource.
+func (g *Group) Close() error {
+	g.once.Do(func() {
+		g.mu.Lock()
+		entries := g.entries
+		g.entries = nil
+		g.closed = true
+		g.mu.Unlock()
+
+		var errs []error
+		for i := len(entries) - 1; i >= 0; i-- {
+			if err := entries[i].close(); err != nil {
+				errs = append(errs, &CloseError{Name: entries[i].name, Err: err})
+			}
+		}
+		g.err = errors.Join(errs...)
+	})
+	return g.err
+}
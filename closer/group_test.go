@@ -0,0 +1,104 @@
+package closer_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/marrbor/goutil/closer"
+	"github.com/stretchr/testify/assert"
+)
+
+type orderClient struct {
+	id    int
+	order *[]int
+	err   error
+}
+
+func (c *orderClient) Close() error {
+	*c.order = append(*c.order, c.id)
+	return c.err
+}
+
+func TestGroup_Close(t *testing.T) {
+	var order []int
+	var g closer.Group
+	assert.NoError(t, g.Add("first", &orderClient{id: 1, order: &order}))
+	assert.NoError(t, g.AddFunc("second", func() error {
+		order = append(order, 2)
+		return nil
+	}))
+	assert.NoError(t, g.AddVoid("third", func() { order = append(order, 3) }))
+	assert.NoError(t, g.Add("fourth", &orderClient{id: 4, order: &order}))
+	assert.EqualValues(t, 4, g.Len())
+
+	assert.NoError(t, g.Close())
+	assert.EqualValues(t, []int{4, 3, 2, 1}, order)
+	assert.EqualValues(t, 0, g.Len())
+
+	// second call does nothing.
+	assert.NoError(t, g.Close())
+	assert.EqualValues(t, []int{4, 3, 2, 1}, order)
+}
+
+func TestGroup_CloseError(t *testing.T) {
+	var order []int
+	var g closer.Group
+	e1 := errors.New("e1")
+	e3 := errors.New("e3")
+	assert.NoError(t, g.Add("db", &orderClient{id: 1, order: &order, err: e1}))
+	assert.NoError(t, g.Add("", &orderClient{id: 2, order: &order}))
+	assert.NoError(t, g.AddFunc("file", func() error { return e3 }))
+
+	err := g.Close()
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, e1))
+	assert.True(t, errors.Is(err, e3))
+	assert.EqualError(t, err, "close file: e3\nclose db: e1")
+
+	var ce *closer.CloseError
+	assert.True(t, errors.As(err, &ce))
+	assert.EqualValues(t, "file", ce.Name)
+
+	// same error is returned again.
+	assert.Equal(t, err, g.Close())
+}
+
+func TestGroup_AddAfterClose(t *testing.T) {
+	var order []int
+	var g closer.Group
+	assert.NoError(t, g.Close())
+
+	err := g.Add("late", &orderClient{id: 1, order: &order})
+	assert.True(t, errors.Is(err, closer.ErrGroupClosed))
+	assert.EqualValues(t, []int{1}, order)
+
+	e := errors.New("late error")
+	err = g.AddFunc("late", func() error { return e })
+	assert.True(t, errors.Is(err, closer.ErrGroupClosed))
+	assert.True(t, errors.Is(err, e))
+}
+
+func TestGroup_Concurrent(t *testing.T) {
+	var g closer.Group
+	var mu sync.Mutex
+	count := 0
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, g.AddVoid("count", func() {
+			mu.Lock()
+			count++
+			mu.Unlock()
+		}))
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, g.Close())
+		}()
+	}
+	wg.Wait()
+	assert.EqualValues(t, 100, count)
+}
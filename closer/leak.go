@@ -0,0 +1,101 @@
+package closer
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+type (
+	// TB is the part of testing.TB used by Track and VerifyNone.
+	TB interface {
+		Helper()
+		Errorf(format string, args ...interface{})
+		Cleanup(func())
+	}
+
+	// tracked is a Client wrapper that counts close calls.
+	tracked struct {
+		cl     Client
+		stack  string
+		mu     sync.Mutex
+		closes int
+	}
+)
+
+var (
+	trackMu  sync.Mutex
+	trackMap = map[TB][]*tracked{}
+)
+
+// Close closes wrapped client and counts the call.
+func (t *tracked) Close() error {
+	t.mu.Lock()
+	t.closes++
+	t.mu.Unlock()
+	return t.cl.Close()
+}
+
+// closeCount returns the number of close calls.
+func (t *tracked) closeCount() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.closes
+}
+
+// callerStack returns stack trace of the caller of the function calling callerStack.
+func callerStack() string {
+	pc := make([]uintptr, 32)
+	n := runtime.Callers(3, pc)
+	frames := runtime.CallersFrames(pc[:n])
+	var sb strings.Builder
+	for {
+		f, more := frames.Next()
+		fmt.Fprintf(&sb, "\t%s\n\t\t%s:%d\n", f.Function, f.File, f.Line)
+		if !more {
+			break
+		}
+	}
+	return sb.String()
+}
+
+// Track wraps given client to detect leak in test. It returns the wrapper that has to be closed instead of given
+// client. When the test finishes, the test fails listing every tracked client that was not closed or closed twice.
+func Track(t TB, cl Client) Client {
+	t.Helper()
+	tc := &tracked{cl: cl, stack: callerStack()}
+
+	trackMu.Lock()
+	_, registered := trackMap[t]
+	trackMap[t] = append(trackMap[t], tc)
+	trackMu.Unlock()
+
+	if !registered {
+		t.Cleanup(func() { VerifyNone(t) })
+	}
+	return tc
+}
+
+// VerifyNone fails the test when a client tracked by Track for given test was not closed or closed twice.
+// Checked clients are not tracked any more.
+func VerifyNone(t TB) {
+	t.Helper()
+	trackMu.Lock()
+	list := trackMap[t]
+	delete(trackMap, t)
+	trackMu.Unlock()
+
+	var msgs []string
+	for _, tc := range list {
+		switch n := tc.closeCount(); {
+		case n == 0:
+			msgs = append(msgs, fmt.Sprintf("%T not closed, created at:\n%s", tc.cl, tc.stack))
+		case n > 1:
+			msgs = append(msgs, fmt.Sprintf("%T closed %d times, created at:\n%s", tc.cl, n, tc.stack))
+		}
+	}
+	if len(msgs) > 0 {
+		t.Errorf("closer: %d leaked resource(s)\n%s", len(msgs), strings.Join(msgs, "\n"))
+	}
+}
@@ -0,0 +1,71 @@
+package closer_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/marrbor/goutil/closer"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTB records failures instead of failing the test.
+type fakeTB struct {
+	errors   []string
+	cleanups []func()
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Errorf(format string, args ...interface{}) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeTB) Cleanup(fn func()) {
+	f.cleanups = append(f.cleanups, fn)
+}
+
+func (f *fakeTB) finish() {
+	for i := len(f.cleanups) - 1; i >= 0; i-- {
+		f.cleanups[i]()
+	}
+}
+
+func TestTrack(t *testing.T) {
+	c := closer.Track(t, &errClient{})
+	closer.Close(c)
+}
+
+func TestTrack_Leak(t *testing.T) {
+	tb := &fakeTB{}
+	closer.Track(tb, &errClient{})
+	double := closer.Track(tb, &errClient{})
+	closed := closer.Track(tb, &errClient{})
+	assert.NoError(t, double.Close())
+	assert.NoError(t, double.Close())
+	assert.NoError(t, closed.Close())
+	assert.EqualValues(t, 1, len(tb.cleanups))
+
+	tb.finish()
+	assert.EqualValues(t, 1, len(tb.errors))
+	assert.Contains(t, tb.errors[0], "2 leaked resource(s)")
+	assert.Contains(t, tb.errors[0], "*closer_test.errClient not closed")
+	assert.Contains(t, tb.errors[0], "*closer_test.errClient closed 2 times")
+	assert.Contains(t, tb.errors[0], "TestTrack_Leak")
+}
+
+func TestVerifyNone(t *testing.T) {
+	tb := &fakeTB{}
+	closer.Track(tb, &errClient{})
+	closer.VerifyNone(tb)
+	assert.EqualValues(t, 1, len(tb.errors))
+
+	// already reported resource is not reported again at cleanup.
+	tb.finish()
+	assert.EqualValues(t, 1, len(tb.errors))
+
+	tb = &fakeTB{}
+	c := closer.Track(tb, &errClient{err: errClose})
+	assert.Error(t, c.Close())
+	closer.VerifyNone(tb)
+	assert.EqualValues(t, 0, len(tb.errors))
+}
@@ -0,0 +1,62 @@
+package closer
+
+import "sync"
+
+// OnceCloser is a Client that closes wrapped client at most once.
+type OnceCloser struct {
+	cl        Client
+	once      sync.Once
+	mu        sync.Mutex
+	closed    bool
+	err       error
+	callbacks []func(error)
+}
+
+// Once returns a Client whose Close calls Close of given client at most once and returns the same error every time.
+func Once(cl Client) *OnceCloser {
+	return &OnceCloser{cl: cl}
+}
+
+// Close closes wrapped client at first call, and returns the error of first call after that.
+// Callbacks registered by OnClose are called with the error after closing by the first caller, so a callback may call
+// Close again.
+func (o *OnceCloser) Close() error {
+	var callbacks []func(error)
+	o.once.Do(func() {
+		err := o.cl.Close()
+		o.mu.Lock()
+		defer o.mu.Unlock()
+		o.closed = true
+		o.err = err
+		callbacks = o.callbacks
+		o.callbacks = nil
+	})
+	o.mu.Lock()
+	err := o.err
+	o.mu.Unlock()
+	for _, f := range callbacks {
+		f(err)
+	}
+	return err
+}
+
+// IsClosed returns whether wrapped client has been closed or not.
+func (o *OnceCloser) IsClosed() bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.closed
+}
+
+// OnClose registers callback called with close error after closing. When already closed, the callback is called
+// immediately.
+func (o *OnceCloser) OnClose(f func(error)) {
+	o.mu.Lock()
+	if !o.closed {
+		o.callbacks = append(o.callbacks, f)
+		o.mu.Unlock()
+		return
+	}
+	err := o.err
+	o.mu.Unlock()
+	f(err)
+}
@@ -0,0 +1,67 @@
+package closer_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/marrbor/goutil/closer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOnce(t *testing.T) {
+	c := &errClient{err: errClose}
+	o := closer.Once(c)
+	assert.False(t, o.IsClosed())
+
+	var got []error
+	o.OnClose(func(err error) { got = append(got, err) })
+
+	assert.Equal(t, errClose, o.Close())
+	assert.Equal(t, errClose, o.Close())
+	assert.True(t, o.IsClosed())
+	assert.EqualValues(t, 1, c.closed)
+	assert.EqualValues(t, []error{errClose}, got)
+
+	// callback registered after close is called immediately.
+	o.OnClose(func(err error) { got = append(got, err) })
+	assert.EqualValues(t, []error{errClose, errClose}, got)
+}
+
+func TestOnce_ReentrantCallback(t *testing.T) {
+	c := &errClient{err: errClose}
+	o := closer.Once(c)
+	var got []error
+	o.OnClose(func(err error) {
+		if o.IsClosed() {
+			got = append(got, o.Close())
+		}
+	})
+
+	done := make(chan error, 1)
+	go func() { done <- o.Close() }()
+	select {
+	case err := <-done:
+		assert.Equal(t, errClose, err)
+	case <-time.After(time.Second):
+		t.Fatal("Close called from callback deadlocked")
+	}
+	assert.EqualValues(t, []error{errClose}, got)
+	assert.EqualValues(t, 1, c.closed)
+}
+
+func TestOnce_Concurrent(t *testing.T) {
+	c := &errClient{}
+	o := closer.Once(c)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			closer.Close(o)
+		}()
+	}
+	wg.Wait()
+	assert.EqualValues(t, 1, c.closed)
+	assert.True(t, o.IsClosed())
+}
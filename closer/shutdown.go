@@ -0,0 +1,201 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	// ExitOK is the exit code when every resource closed successfully.
+	ExitOK = 0
+	// ExitFailure is the exit code when some resource failed to close or did not close within grace period.
+	ExitFailure = 1
+)
+
+type (
+	// Shutdown closes registered resources phase by phase when it receives an OS signal.
+	// Resources are closed in ascending phase order and resources in the same phase are closed concurrently.
+	// Whole shutdown has to end within the grace period, and receiving second signal while shutting down forces
+	// the process to exit. Resources left when the grace period expires are still closed in background and reported
+	// with context.DeadlineExceeded.
+	Shutdown struct {
+		grace   time.Duration
+		signals []os.Signal
+		exit    func(int)
+
+		mu      sync.Mutex
+		entries []shutdownEntry
+
+		sigCh     chan os.Signal
+		triggerCh chan struct{}
+		trigger   sync.Once
+		wait      sync.Once
+		report    *Report
+	}
+
+	shutdownEntry struct {
+		phase int
+		name  string
+		cl    interface{}
+	}
+
+	// Result is an outcome of closing one resource.
+	Result struct {
+		Phase    int
+		Name     string
+		Err      error
+		Duration time.Duration
+	}
+
+	// Report is an outcome of shutdown.
+	Report struct {
+		Signal  os.Signal // received signal, nil when shutdown started by Trigger.
+		Results []Result  // results ordered by phase and registration order.
+	}
+)
+
+// NewShutdown returns new instance of Shutdown that starts listening given signals (default: SIGINT and SIGTERM).
+func NewShutdown(grace time.Duration, signals ...os.Signal) *Shutdown {
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	s := &Shutdown{
+		grace:     grace,
+		signals:   signals,
+		exit:      os.Exit,
+		sigCh:     make(chan os.Signal, 2),
+		triggerCh: make(chan struct{}),
+	}
+	signal.Notify(s.sigCh, signals...)
+	return s
+}
+
+// SetExit replaces the function called to force exit on second signal (default: os.Exit).
+func (s *Shutdown) SetExit(f func(code int)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.exit = f
+}
+
+// Add registers given Client or ContextCloser to be closed in given phase.
+func (s *Shutdown) Add(phase int, name string, cl interface{}) error {
+	switch cl.(type) {
+	case Client, ContextCloser:
+	default:
+		return fmt.Errorf("%w: %T", ErrNotCloser, cl)
+	}
+	if name == "" {
+		name = fmt.Sprintf("%T", cl)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, shutdownEntry{phase: phase, name: name, cl: cl})
+	return nil
+}
+
+// Trigger starts shutdown without signal.
+func (s *Shutdown) Trigger() {
+	s.trigger.Do(func() { close(s.triggerCh) })
+}
+
+// Wait blocks until a signal is received or Trigger is called, then closes every registered resource and returns
+// the report. Calling Wait several times returns the same report.
+func (s *Shutdown) Wait() *Report {
+	s.wait.Do(func() {
+		r := &Report{}
+		select {
+		case r.Signal = <-s.sigCh:
+		case <-s.triggerCh:
+		}
+
+		// force exit on second signal.
+		done := make(chan struct{})
+		watched := make(chan struct{})
+		go func() {
+			defer close(watched)
+			select {
+			case sig := <-s.sigCh:
+				s.mu.Lock()
+				exit := s.exit
+				s.mu.Unlock()
+				exit(signalExitCode(sig))
+			case <-done:
+			}
+		}()
+
+		r.Results = s.closeAll()
+		close(done)
+		<-watched
+		signal.Stop(s.sigCh)
+		s.report = r
+	})
+	return s.report
+}
+
+// closeAll closes registered resources phase by phase within grace period.
+func (s *Shutdown) closeAll() []Result {
+	s.mu.Lock()
+	entries := make([]shutdownEntry, len(s.entries))
+	copy(entries, s.entries)
+	s.mu.Unlock()
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].phase < entries[j].phase })
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.grace)
+	defer cancel()
+
+	results := make([]Result, len(entries))
+	for start := 0; start < len(entries); {
+		end := start
+		for end < len(entries) && entries[end].phase == entries[start].phase {
+			end++
+		}
+
+		var wg sync.WaitGroup
+		for i := start; i < end; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				begin := time.Now()
+				err := CloseContext(ctx, entries[i].cl)
+				results[i] = Result{Phase: entries[i].phase, Name: entries[i].name, Err: err, Duration: time.Since(begin)}
+			}(i)
+		}
+		wg.Wait()
+		start = end
+	}
+	return results
+}
+
+// signalExitCode returns exit code for the process terminated by given signal.
+func signalExitCode(sig os.Signal) int {
+	if s, ok := sig.(syscall.Signal); ok {
+		return 128 + int(s)
+	}
+	return ExitFailure
+}
+
+// Err returns joined error of failed resources, nil when every resource closed successfully.
+func (r *Report) Err() error {
+	var errs []error
+	for _, res := range r.Results {
+		if res.Err != nil {
+			errs = append(errs, &CloseError{Name: res.Name, Err: res.Err})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ExitCode returns ExitOK when every resource closed successfully, otherwise ExitFailure.
+func (r *Report) ExitCode() int {
+	if r.Err() != nil {
+		return ExitFailure
+	}
+	return ExitOK
+}
@@ -0,0 +1,109 @@
+//go:build unix
+
+package closer_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/marrbor/goutil/closer"
+	"github.com/stretchr/testify/assert"
+)
+
+// sendSignal sends given signal to this test process.
+func sendSignal(t *testing.T, sig os.Signal) {
+	p, err := os.FindProcess(os.Getpid())
+	assert.NoError(t, err)
+	assert.NoError(t, p.Signal(sig))
+}
+
+type phaseClient struct {
+	mu    *sync.Mutex
+	order *[]string
+	name  string
+	err   error
+}
+
+func (c *phaseClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	*c.order = append(*c.order, c.name)
+	return c.err
+}
+
+func TestShutdown_Signal(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	s := closer.NewShutdown(time.Second, syscall.SIGUSR1)
+	assert.NoError(t, s.Add(2, "db", &phaseClient{mu: &mu, order: &order, name: "db"}))
+	assert.NoError(t, s.Add(1, "http", &phaseClient{mu: &mu, order: &order, name: "http"}))
+	assert.NoError(t, s.Add(3, "", &phaseClient{mu: &mu, order: &order, name: "log"}))
+	assert.Error(t, s.Add(1, "invalid", 123))
+
+	sendSignal(t, syscall.SIGUSR1)
+	r := s.Wait()
+	assert.EqualValues(t, syscall.SIGUSR1, r.Signal)
+	assert.EqualValues(t, []string{"http", "db", "log"}, order)
+	assert.NoError(t, r.Err())
+	assert.EqualValues(t, closer.ExitOK, r.ExitCode())
+	assert.EqualValues(t, 3, len(r.Results))
+	assert.EqualValues(t, "http", r.Results[0].Name)
+	assert.EqualValues(t, "*closer_test.phaseClient", r.Results[2].Name)
+
+	// same report is returned again.
+	assert.Equal(t, r, s.Wait())
+}
+
+func TestShutdown_Failure(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	e := errors.New("db failed")
+	hang := &hangClient{release: make(chan struct{})}
+	defer close(hang.release)
+
+	s := closer.NewShutdown(20*time.Millisecond, syscall.SIGUSR1)
+	assert.NoError(t, s.Add(0, "db", &phaseClient{mu: &mu, order: &order, name: "db", err: e}))
+	assert.NoError(t, s.Add(0, "hang", hang))
+	assert.NoError(t, s.Add(1, "late", &phaseClient{mu: &mu, order: &order, name: "late"}))
+
+	s.Trigger()
+	r := s.Wait()
+	assert.Nil(t, r.Signal)
+	assert.True(t, errors.Is(r.Err(), e))
+	assert.True(t, errors.Is(r.Results[1].Err, context.DeadlineExceeded))
+	assert.True(t, errors.Is(r.Results[2].Err, context.DeadlineExceeded))
+	assert.EqualValues(t, closer.ExitFailure, r.ExitCode())
+
+	// the later phase is still closed after the grace period.
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(order) == 2 && order[1] == "late"
+	}, time.Second, time.Millisecond)
+}
+
+func TestShutdown_ForceExit(t *testing.T) {
+	hang := &hangClient{release: make(chan struct{})}
+	s := closer.NewShutdown(time.Minute, syscall.SIGUSR2)
+	assert.NoError(t, s.Add(0, "hang", hang))
+
+	exitCh := make(chan int, 1)
+	s.SetExit(func(code int) {
+		exitCh <- code
+		close(hang.release) // let Wait end instead of exiting test process.
+	})
+
+	sendSignal(t, syscall.SIGUSR2)
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		sendSignal(t, syscall.SIGUSR2)
+	}()
+	r := s.Wait()
+	assert.EqualValues(t, 128+int(syscall.SIGUSR2), <-exitCh)
+	assert.NoError(t, r.Err())
+}
@@ -0,0 +1,235 @@
+package enc
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// AEADAlgorithm is an authenticated encryption algorithm. Its value is stored in the envelope.
+type AEADAlgorithm byte
+
+const (
+	AES256GCM        AEADAlgorithm = 1
+	ChaCha20Poly1305 AEADAlgorithm = 2
+)
+
+const (
+	// EnvelopeVersion is the version of envelope generated by Seal.
+	EnvelopeVersion = 1
+
+	// KeySize is the size of the key of every AEADAlgorithm.
+	KeySize = 32
+)
+
+var (
+	ErrInvalidKey      = errors.New("invalid key")
+	ErrInvalidEnvelope = errors.New("invalid envelope")
+	ErrKeyMismatch     = errors.New("key id mismatch")
+	ErrDecrypt         = errors.New("message authentication failed")
+)
+
+// String returns the name of the algorithm.
+func (a AEADAlgorithm) String() string {
+	switch a {
+	case AES256GCM:
+		return "AES-256-GCM"
+	case ChaCha20Poly1305:
+		return "ChaCha20-Poly1305"
+	}
+	return fmt.Sprintf("AEADAlgorithm(%d)", byte(a))
+}
+
+// ParseAEADAlgorithm returns the algorithm that has given name.
+func ParseAEADAlgorithm(name string) (AEADAlgorithm, error) {
+	for _, a := range []AEADAlgorithm{AES256GCM, ChaCha20Poly1305} {
+		if a.String() == name {
+			return a, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %s", ErrUnknownAlgorithm, name)
+}
+
+// MarshalText returns the name of the algorithm.
+func (a AEADAlgorithm) MarshalText() ([]byte, error) {
+	if nonceSize(a) == 0 {
+		return nil, fmt.Errorf("%w: %v", ErrUnknownAlgorithm, a)
+	}
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText parses the name of the algorithm.
+func (a *AEADAlgorithm) UnmarshalText(b []byte) error {
+	alg, err := ParseAEADAlgorithm(string(b))
+	if err != nil {
+		return err
+	}
+	*a = alg
+	return nil
+}
+
+type (
+	// Key is a symmetric key for authenticated encryption.
+	Key struct {
+		ID        string        // key id stored in the envelope, up to 255 bytes.
+		Algorithm AEADAlgorithm // encryption algorithm.
+		Secret    []byte        // KeySize bytes secret.
+	}
+
+	// Envelope is a parsed ciphertext generated by Seal.
+	Envelope struct {
+		Version    byte
+		Algorithm  AEADAlgorithm
+		KeyID      string
+		Nonce      []byte
+		Ciphertext []byte // encrypted message followed by authentication tag.
+		header     []byte // authenticated as associated data.
+	}
+)
+
+// NewKey returns a new key that has given id and random secret.
+func NewKey(id string, alg AEADAlgorithm) (*Key, error) {
+	secret := make([]byte, KeySize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	k := &Key{ID: id, Algorithm: alg, Secret: secret}
+	if _, err := k.aead(); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// aead returns cipher.AEAD of the key.
+func (k *Key) aead() (cipher.AEAD, error) {
+	if len(k.ID) > 255 {
+		return nil, fmt.Errorf("%w: key id too long", ErrInvalidKey)
+	}
+	if len(k.Secret) != KeySize {
+		return nil, fmt.Errorf("%w: secret must be %d bytes", ErrInvalidKey, KeySize)
+	}
+	switch k.Algorithm {
+	case AES256GCM:
+		b, err := aes.NewCipher(k.Secret)
+		if err != nil {
+			return nil, err
+		}
+		return cipher.NewGCM(b)
+	case ChaCha20Poly1305:
+		return chacha20poly1305.New(k.Secret)
+	}
+	return nil, fmt.Errorf("%w: %v", ErrUnknownAlgorithm, k.Algorithm)
+}
+
+// envelopeHeader returns the envelope header for given version and key.
+func envelopeHeader(version byte, k *Key) []byte {
+	h := make([]byte, 0, 3+len(k.ID))
+	h = append(h, version, byte(k.Algorithm), byte(len(k.ID)))
+	return append(h, k.ID...)
+}
+
+// Seal encrypts and authenticates given plaintext and authenticates given associated data (may be nil) with
+// given key. The returned envelope consists of version, algorithm, key id, random nonce and ciphertext.
+// The same associated data has to be given to Open.
+func Seal(k *Key, plaintext, ad []byte) ([]byte, error) {
+	a, err := k.aead()
+	if err != nil {
+		return nil, err
+	}
+	header := envelopeHeader(EnvelopeVersion, k)
+	out := make([]byte, len(header)+a.NonceSize(), len(header)+a.NonceSize()+len(plaintext)+a.Overhead())
+	copy(out, header)
+	nonce := out[len(header):]
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return a.Seal(out, nonce, plaintext, append(header, ad...)), nil
+}
+
+// Open decrypts given envelope generated by Seal and returns the plaintext.
+// It returns ErrKeyMismatch when the envelope was sealed with another key.
+func Open(k *Key, envelope, ad []byte) ([]byte, error) {
+	e, err := ParseEnvelope(envelope)
+	if err != nil {
+		return nil, err
+	}
+	return e.open(k, ad)
+}
+
+// open decrypts the envelope with given key.
+func (e *Envelope) open(k *Key, ad []byte) ([]byte, error) {
+	if e.KeyID != k.ID || e.Algorithm != k.Algorithm {
+		return nil, fmt.Errorf("%w: sealed with %q (%v)", ErrKeyMismatch, e.KeyID, e.Algorithm)
+	}
+	a, err := k.aead()
+	if err != nil {
+		return nil, err
+	}
+	if len(e.Nonce) != a.NonceSize() || len(e.Ciphertext) < a.Overhead() {
+		return nil, ErrInvalidEnvelope
+	}
+	pt, err := a.Open(nil, e.Nonce, e.Ciphertext, append(e.header[:len(e.header):len(e.header)], ad...))
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return pt, nil
+}
+
+// ParseEnvelope parses given envelope generated by Seal without decrypting it.
+func ParseEnvelope(b []byte) (*Envelope, error) {
+	if len(b) < 3 {
+		return nil, ErrInvalidEnvelope
+	}
+	if b[0] != EnvelopeVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidEnvelope, b[0])
+	}
+	e := &Envelope{Version: b[0], Algorithm: AEADAlgorithm(b[1])}
+	ns := nonceSize(e.Algorithm)
+	if ns == 0 {
+		return nil, fmt.Errorf("%w: %v", ErrUnknownAlgorithm, e.Algorithm)
+	}
+	hl := 3 + int(b[2])
+	if len(b) < hl+ns {
+		return nil, ErrInvalidEnvelope
+	}
+	e.header = b[:hl]
+	e.KeyID = string(b[3:hl])
+	e.Nonce = b[hl : hl+ns]
+	e.Ciphertext = b[hl+ns:]
+	return e, nil
+}
+
+// nonceSize returns nonce size of given algorithm, 0 for unknown algorithm.
+func nonceSize(alg AEADAlgorithm) int {
+	switch alg {
+	case AES256GCM, ChaCha20Poly1305:
+		return 12
+	}
+	return 0
+}
+
+// b64url is the base64 encoding used for string form of envelope.
+var b64url = base64.RawURLEncoding
+
+// SealString is Seal that returns the envelope encoded in unpadded base64url.
+func SealString(k *Key, plaintext, ad []byte) (string, error) {
+	b, err := Seal(k, plaintext, ad)
+	if err != nil {
+		return "", err
+	}
+	return b64url.EncodeToString(b), nil
+}
+
+// OpenString is Open for the envelope encoded by SealString.
+func OpenString(k *Key, envelope string, ad []byte) ([]byte, error) {
+	b, err := b64url.DecodeString(envelope)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEnvelope, err)
+	}
+	return Open(k, b, ad)
+}
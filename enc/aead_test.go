@@ -0,0 +1,93 @@
+package enc_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/marrbor/goutil/enc"
+	"github.com/stretchr/testify/assert"
+)
+
+var aeadAlgorithms = []enc.AEADAlgorithm{enc.AES256GCM, enc.ChaCha20Poly1305}
+
+func TestSealOpen(t *testing.T) {
+	for _, alg := range aeadAlgorithms {
+		k, err := enc.NewKey("k1", alg)
+		assert.NoError(t, err)
+
+		msg := []byte("hello, world")
+		ad := []byte("user:123")
+		c, err := enc.Seal(k, msg, ad)
+		assert.NoError(t, err)
+
+		e, err := enc.ParseEnvelope(c)
+		assert.NoError(t, err)
+		assert.EqualValues(t, enc.EnvelopeVersion, e.Version)
+		assert.EqualValues(t, alg, e.Algorithm)
+		assert.EqualValues(t, "k1", e.KeyID)
+		assert.EqualValues(t, 12, len(e.Nonce))
+
+		p, err := enc.Open(k, c, ad)
+		assert.NoError(t, err)
+		assert.EqualValues(t, msg, p)
+
+		// nonce is random.
+		c2, err := enc.Seal(k, msg, ad)
+		assert.NoError(t, err)
+		assert.NotEqual(t, c, c2)
+
+		// wrong associated data.
+		_, err = enc.Open(k, c, []byte("user:124"))
+		assert.True(t, errors.Is(err, enc.ErrDecrypt))
+
+		// tampered ciphertext and header.
+		for _, i := range []int{len(c) - 1, 5, 3} {
+			tc := append([]byte{}, c...)
+			tc[i] ^= 1
+			_, err = enc.Open(k, tc, ad)
+			assert.Error(t, err, i)
+		}
+
+		// another key.
+		k2, err := enc.NewKey("k2", alg)
+		assert.NoError(t, err)
+		_, err = enc.Open(k2, c, ad)
+		assert.True(t, errors.Is(err, enc.ErrKeyMismatch))
+		k2.ID = "k1"
+		_, err = enc.Open(k2, c, ad)
+		assert.True(t, errors.Is(err, enc.ErrDecrypt))
+	}
+}
+
+func TestSealOpenString(t *testing.T) {
+	k, err := enc.NewKey("", enc.AES256GCM)
+	assert.NoError(t, err)
+	s, err := enc.SealString(k, []byte("secret"), nil)
+	assert.NoError(t, err)
+	assert.NotContains(t, s, "=")
+
+	p, err := enc.OpenString(k, s, nil)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "secret", string(p))
+
+	_, err = enc.OpenString(k, "!"+s, nil)
+	assert.True(t, errors.Is(err, enc.ErrInvalidEnvelope))
+}
+
+func TestParseEnvelope(t *testing.T) {
+	for _, b := range [][]byte{nil, {1, 1}, {2, 1, 0}, {1, 1, 5, 'a'}, {1, 1, 0, 1, 2, 3}} {
+		_, err := enc.ParseEnvelope(b)
+		assert.True(t, errors.Is(err, enc.ErrInvalidEnvelope), b)
+	}
+	_, err := enc.ParseEnvelope([]byte{1, 9, 0})
+	assert.True(t, errors.Is(err, enc.ErrUnknownAlgorithm))
+}
+
+func TestKey_Invalid(t *testing.T) {
+	_, err := enc.Seal(&enc.Key{Algorithm: enc.AES256GCM, Secret: []byte("short")}, nil, nil)
+	assert.True(t, errors.Is(err, enc.ErrInvalidKey))
+	_, err = enc.NewKey("k", enc.AEADAlgorithm(9))
+	assert.True(t, errors.Is(err, enc.ErrUnknownAlgorithm))
+	assert.EqualValues(t, "AES-256-GCM", enc.AES256GCM.String())
+	assert.EqualValues(t, "AEADAlgorithm(9)", enc.AEADAlgorithm(9).String())
+}
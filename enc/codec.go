@@ -0,0 +1,366 @@
+package enc
+
+import (
+	"bytes"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"unicode/utf8"
+)
+
+// Codec is a binary-to-text encoding.
+type Codec interface {
+	// EncodeToString returns encoded string of given data.
+	EncodeToString(src []byte) string
+	// DecodeString returns decoded data of given string. The error is *InvalidCharError for a bad character.
+	DecodeString(s string) ([]byte, error)
+	// NewEncoder returns a writer that writes encoded data to w. Close has to be called to flush it.
+	NewEncoder(w io.Writer) io.WriteCloser
+	// NewDecoder returns a reader that decodes data read from r.
+	NewDecoder(r io.Reader) io.Reader
+}
+
+// ErrChecksum is returned when the check symbol of Crockford Base32 does not match.
+var ErrChecksum = errors.New("checksum mismatch")
+
+// InvalidCharError is an error of decoding that reports the bad character and its byte position.
+type InvalidCharError struct {
+	Encoding string
+	Pos      int
+	Char     rune
+	Reason   string
+}
+
+// Error returns error string.
+func (e *InvalidCharError) Error() string {
+	reason := e.Reason
+	if reason == "" {
+		reason = "invalid character"
+	}
+	return fmt.Sprintf("%s: %s %q at position %d", e.Encoding, reason, e.Char, e.Pos)
+}
+
+// Alphabets.
+const (
+	base58Alphabet    = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+	base62Alphabet    = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	crockfordCheck    = "*~$=U"
+	zbase32Alphabet   = "ybndrfg8ejkmcpqxot1uwisza345h769"
+)
+
+var (
+	// Base58 is Base58 encoding with Bitcoin alphabet. Leading zero bytes are encoded as '1'.
+	Base58 Codec = newRadixCodec("base58", base58Alphabet)
+
+	// Base62 is Base62 encoding with 0-9A-Za-z alphabet. Leading zero bytes are encoded as '0'.
+	Base62 Codec = newRadixCodec("base62", base62Alphabet)
+
+	// Crockford32 is Crockford Base32 encoding without check symbol. Decoding is case insensitive, accepts
+	// 'I', 'L' as '1' and 'O' as '0', and ignores hyphens.
+	Crockford32 Codec = newBase32Codec("crockford32", crockfordAlphabet, crockfordNormalize)
+
+	// ZBase32 is human-oriented z-base-32 encoding.
+	ZBase32 Codec = newBase32Codec("zbase32", zbase32Alphabet, func(c byte) (byte, bool) { return c, true })
+)
+
+// radixCodec encodes data as a big number in given radix, like Base58 and Base62.
+// Every output digit depends on the whole input, so its encoder and decoder buffer whole data.
+type radixCodec struct {
+	name     string
+	alphabet string
+	decode   [256]int8
+}
+
+// newRadixCodec returns radixCodec for given alphabet.
+func newRadixCodec(name, alphabet string) *radixCodec {
+	c := &radixCodec{name: name, alphabet: alphabet}
+	for i := range c.decode {
+		c.decode[i] = -1
+	}
+	for i := 0; i < len(alphabet); i++ {
+		c.decode[alphabet[i]] = int8(i)
+	}
+	return c
+}
+
+func (c *radixCodec) EncodeToString(src []byte) string {
+	zeros := 0
+	for zeros < len(src) && src[zeros] == 0 {
+		zeros++
+	}
+	radix := big.NewInt(int64(len(c.alphabet)))
+	n := new(big.Int).SetBytes(src[zeros:])
+	mod := new(big.Int)
+	var out []byte
+	for n.Sign() > 0 {
+		n.DivMod(n, radix, mod)
+		out = append(out, c.alphabet[mod.Int64()])
+	}
+	for i := 0; i < zeros; i++ {
+		out = append(out, c.alphabet[0])
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+func (c *radixCodec) DecodeString(s string) ([]byte, error) {
+	zeros := 0
+	for zeros < len(s) && s[zeros] == c.alphabet[0] {
+		zeros++
+	}
+	radix := big.NewInt(int64(len(c.alphabet)))
+	n := new(big.Int)
+	for i := zeros; i < len(s); i++ {
+		d := c.decode[s[i]]
+		if d < 0 {
+			return nil, c.invalidChar(s, i)
+		}
+		n.Mul(n, radix)
+		n.Add(n, big.NewInt(int64(d)))
+	}
+	return append(make([]byte, zeros), n.Bytes()...), nil
+}
+
+// invalidChar returns InvalidCharError for the character at given position.
+func (c *radixCodec) invalidChar(s string, pos int) error {
+	r := []rune(s[pos:])[0]
+	return &InvalidCharError{Encoding: c.name, Pos: pos, Char: r}
+}
+
+func (c *radixCodec) NewEncoder(w io.Writer) io.WriteCloser {
+	return &bufferedEncoder{w: w, encode: c.EncodeToString}
+}
+
+func (c *radixCodec) NewDecoder(r io.Reader) io.Reader {
+	return &bufferedDecoder{r: r, decode: c.DecodeString}
+}
+
+type (
+	// bufferedEncoder encodes whole written data on Close.
+	bufferedEncoder struct {
+		w      io.Writer
+		buf    bytes.Buffer
+		encode func([]byte) string
+	}
+
+	// bufferedDecoder decodes whole data of underlying reader on first Read.
+	bufferedDecoder struct {
+		r      io.Reader
+		decode func(string) ([]byte, error)
+		out    *bytes.Reader
+		err    error
+	}
+)
+
+func (e *bufferedEncoder) Write(p []byte) (int, error) {
+	return e.buf.Write(p)
+}
+
+func (e *bufferedEncoder) Close() error {
+	_, err := io.WriteString(e.w, e.encode(e.buf.Bytes()))
+	e.buf.Reset()
+	return err
+}
+
+func (d *bufferedDecoder) Read(p []byte) (int, error) {
+	if d.out == nil && d.err == nil {
+		b, err := io.ReadAll(d.r)
+		if err == nil {
+			b, err = d.decode(strings.TrimSpace(string(b)))
+		}
+		d.out, d.err = bytes.NewReader(b), err
+	}
+	if d.err != nil {
+		return 0, d.err
+	}
+	return d.out.Read(p)
+}
+
+// base32Codec is unpadded Base32 encoding with custom alphabet.
+type base32Codec struct {
+	name      string
+	alphabet  string
+	normalize func(c byte) (byte, bool) // returns the character in alphabet and false to skip it.
+	enc       *base32.Encoding
+}
+
+// newBase32Codec returns base32Codec for given alphabet.
+func newBase32Codec(name, alphabet string, normalize func(c byte) (byte, bool)) *base32Codec {
+	return &base32Codec{
+		name:      name,
+		alphabet:  alphabet,
+		normalize: normalize,
+		enc:       base32.NewEncoding(alphabet).WithPadding(base32.NoPadding),
+	}
+}
+
+func (c *base32Codec) EncodeToString(src []byte) string {
+	return c.enc.EncodeToString(src)
+}
+
+func (c *base32Codec) DecodeString(s string) ([]byte, error) {
+	norm := make([]byte, 0, len(s))
+	pos := make([]int, 0, len(s)) // original position of each normalized character.
+	for i := 0; i < len(s); i++ {
+		b, ok := c.normalize(s[i])
+		if !ok {
+			continue
+		}
+		if strings.IndexByte(c.alphabet, b) < 0 {
+			return nil, &InvalidCharError{Encoding: c.name, Pos: i, Char: []rune(s[i:])[0]}
+		}
+		norm = append(norm, b)
+		pos = append(pos, i)
+	}
+
+	out, err := c.enc.DecodeString(string(norm))
+	if err != nil || c.enc.EncodeToString(out) != string(norm) {
+		// invalid length or non-zero trailing bits.
+		last := len(norm) - 1
+		if last < 0 {
+			return nil, &InvalidCharError{Encoding: c.name, Reason: "invalid length"}
+		}
+		return nil, &InvalidCharError{Encoding: c.name, Pos: pos[last], Char: rune(s[pos[last]]), Reason: "non-canonical last character"}
+	}
+	return out, nil
+}
+
+func (c *base32Codec) NewEncoder(w io.Writer) io.WriteCloser {
+	return base32.NewEncoder(c.enc, w)
+}
+
+func (c *base32Codec) NewDecoder(r io.Reader) io.Reader {
+	return &base32Decoder{r: r, codec: c}
+}
+
+// base32Decoder decodes base32Codec read from underlying reader by blocks of 8 characters. It reports a bad
+// character, and a bad last character at EOF, as *InvalidCharError with the position in the original input like
+// DecodeString.
+type base32Decoder struct {
+	r       io.Reader
+	codec   *base32Codec
+	buf     [512]byte
+	pending []byte // normalized characters not decoded yet.
+	out     []byte // decoded data not read yet.
+	err     error
+	off     int  // bytes read from underlying reader.
+	last    byte // last normalized character.
+	lastOrg byte // original character of last.
+	lastPos int  // original position of last.
+}
+
+func (d *base32Decoder) Read(p []byte) (int, error) {
+	for len(d.out) == 0 && d.err == nil {
+		d.fill()
+	}
+	if len(d.out) > 0 {
+		n := copy(p, d.out)
+		d.out = d.out[n:]
+		return n, nil
+	}
+	return 0, d.err
+}
+
+// fill reads underlying reader once and decodes complete blocks, and the last block at EOF.
+func (d *base32Decoder) fill() {
+	l, err := d.r.Read(d.buf[:])
+	for i := 0; i < l; i++ {
+		b, ok := d.codec.normalize(d.buf[i])
+		if !ok {
+			continue
+		}
+		if strings.IndexByte(d.codec.alphabet, b) < 0 {
+			ch, _ := utf8.DecodeRune(d.buf[i:l])
+			err = &InvalidCharError{Encoding: d.codec.name, Pos: d.off + i, Char: ch}
+			break
+		}
+		d.pending = append(d.pending, b)
+		d.last, d.lastOrg, d.lastPos = b, d.buf[i], d.off+i
+	}
+	d.off += l
+
+	full := len(d.pending) / 8 * 8
+	d.out = d.decode(d.out, d.pending[:full])
+	d.pending = append(d.pending[:0], d.pending[full:]...)
+	if err != io.EOF {
+		d.err = err
+		return
+	}
+	// unused bits of the last character by the number of characters in the last block, -1 for invalid length.
+	unused := [8]int{0, -1, 2, -1, 4, 1, -1, 3}[len(d.pending)]
+	if unused < 0 || strings.IndexByte(d.codec.alphabet, d.last)&(1<<unused-1) != 0 {
+		d.err = &InvalidCharError{Encoding: d.codec.name, Pos: d.lastPos, Char: rune(d.lastOrg), Reason: "non-canonical last character"}
+		return
+	}
+	d.out = d.decode(d.out, d.pending)
+	d.pending = d.pending[:0]
+	d.err = io.EOF
+}
+
+// decode appends decoded data of given normalized characters, which never fail, to dst.
+func (d *base32Decoder) decode(dst, src []byte) []byte {
+	if len(src) == 0 {
+		return dst
+	}
+	b := make([]byte, d.codec.enc.DecodedLen(len(src)))
+	n, _ := d.codec.enc.Decode(b, src)
+	return append(dst, b[:n]...)
+}
+
+// crockfordNormalize maps a character of Crockford Base32 to the canonical one.
+func crockfordNormalize(c byte) (byte, bool) {
+	switch c {
+	case '-', '\n', '\r':
+		return 0, false
+	case 'I', 'i', 'L', 'l':
+		return '1', true
+	case 'O', 'o':
+		return '0', true
+	}
+	if 'a' <= c && c <= 'z' {
+		return c - 'a' + 'A', true
+	}
+	return c, true
+}
+
+// crockfordMod37 returns given data as a big endian number modulo 37.
+func crockfordMod37(src []byte) int {
+	m := 0
+	for _, b := range src {
+		m = (m*256 + int(b)) % 37
+	}
+	return m
+}
+
+// EncodeCrockfordCheck returns Crockford Base32 string of given data followed by its check symbol.
+func EncodeCrockfordCheck(src []byte) string {
+	return Crockford32.EncodeToString(src) + string((crockfordAlphabet + crockfordCheck)[crockfordMod37(src)])
+}
+
+// DecodeCrockfordCheck decodes Crockford Base32 string followed by check symbol generated by EncodeCrockfordCheck.
+func DecodeCrockfordCheck(s string) ([]byte, error) {
+	s = strings.TrimRight(s, "-")
+	if s == "" {
+		return nil, &InvalidCharError{Encoding: "crockford32", Reason: "missing check symbol"}
+	}
+	last := len(s) - 1
+	check, _ := crockfordNormalize(s[last])
+	want := strings.IndexByte(crockfordAlphabet+crockfordCheck, check)
+	if want < 0 {
+		return nil, &InvalidCharError{Encoding: "crockford32", Pos: last, Char: rune(s[last]), Reason: "invalid check symbol"}
+	}
+	out, err := Crockford32.DecodeString(s[:last])
+	if err != nil {
+		return nil, err
+	}
+	if crockfordMod37(out) != want {
+		return nil, fmt.Errorf("crockford32: %w", ErrChecksum)
+	}
+	return out, nil
+}
@@ -0,0 +1,181 @@
+package enc_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/marrbor/goutil/enc"
+	"github.com/stretchr/testify/assert"
+)
+
+var codecs = map[string]enc.Codec{
+	"base58":      enc.Base58,
+	"base62":      enc.Base62,
+	"crockford32": enc.Crockford32,
+	"zbase32":     enc.ZBase32,
+}
+
+func TestCodec_Vectors(t *testing.T) {
+	hexDecode := func(s string) []byte {
+		b, err := hex.DecodeString(s)
+		assert.NoError(t, err)
+		return b
+	}
+	vectors := []struct {
+		codec enc.Codec
+		in    []byte
+		out   string
+	}{
+		// https://datatracker.ietf.org/doc/html/draft-msporny-base58
+		{enc.Base58, []byte("Hello World!"), "2NEpo7TZRRrLZSi2U"},
+		{enc.Base58, []byte("The quick brown fox jumps over the lazy dog."), "USm3fpXnKG5EUBx2ndxBDMPVciP5hGey2Jh4NDv6gmeo1LkMeiKrLJUUBk6Z"},
+		{enc.Base58, hexDecode("0000287fb4cd"), "11233QC4"},
+		{enc.Base62, []byte("Hello World!"), "T8dgcjRGkZ3aysdN"},
+		{enc.Base62, []byte{0, 0, 1}, "001"},
+		{enc.Crockford32, []byte("foobar"), "CSQPYRK1E8"},
+		{enc.ZBase32, []byte{0xf0, 0xbf, 0xc7}, "6n9hq"},
+		{enc.ZBase32, []byte{0xd4, 0x7a, 0x04}, "4t7ye"},
+	}
+	for _, v := range vectors {
+		assert.EqualValues(t, v.out, v.codec.EncodeToString(v.in))
+		d, err := v.codec.DecodeString(v.out)
+		assert.NoError(t, err)
+		assert.EqualValues(t, v.in, d)
+	}
+}
+
+func TestCodec_RoundTrip(t *testing.T) {
+	inputs := [][]byte{{}, {0}, {0, 0, 0}, {255}, []byte("goutil"), bytes.Repeat([]byte{0xa5}, 100)}
+	for name, c := range codecs {
+		for _, in := range inputs {
+			s := c.EncodeToString(in)
+			out, err := c.DecodeString(s)
+			assert.NoError(t, err, name)
+			assert.True(t, bytes.Equal(in, out), "%s %x", name, in)
+
+			// streaming variants.
+			var buf bytes.Buffer
+			w := c.NewEncoder(&buf)
+			for _, b := range in {
+				_, err = w.Write([]byte{b})
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, w.Close())
+			assert.EqualValues(t, s, buf.String(), name)
+
+			out, err = io.ReadAll(c.NewDecoder(strings.NewReader(s)))
+			assert.NoError(t, err, name)
+			assert.True(t, bytes.Equal(in, out), "%s %x", name, in)
+			out, err = io.ReadAll(c.NewDecoder(iotest.OneByteReader(strings.NewReader(s))))
+			assert.NoError(t, err, name)
+			assert.True(t, bytes.Equal(in, out), "%s %x", name, in)
+		}
+	}
+}
+
+func TestCodec_InvalidChar(t *testing.T) {
+	tests := []struct {
+		codec enc.Codec
+		in    string
+		pos   int
+		char  rune
+	}{
+		{enc.Base58, "2NEpo0TZ", 5, '0'},
+		{enc.Base58, "abcl", 3, 'l'},
+		{enc.Base62, "ab-c", 2, '-'},
+		{enc.Base62, "abあ", 2, 'あ'},
+		{enc.Crockford32, "CSQPU", 4, 'U'},
+		{enc.ZBase32, "6n9hl", 4, 'l'},
+	}
+	for _, tt := range tests {
+		_, err := tt.codec.DecodeString(tt.in)
+		var ie *enc.InvalidCharError
+		assert.True(t, errors.As(err, &ie), tt.in)
+		assert.EqualValues(t, tt.pos, ie.Pos, tt.in)
+		assert.EqualValues(t, tt.char, ie.Char, tt.in)
+	}
+
+	// non-canonical last character and invalid length.
+	_, err := enc.ZBase32.DecodeString("6n9hb")
+	assert.Error(t, err)
+	_, err = enc.Crockford32.DecodeString("C")
+	assert.Error(t, err)
+
+	_, err = io.ReadAll(enc.Base58.NewDecoder(strings.NewReader("0")))
+	assert.Error(t, err)
+
+	// streaming base32 decoders report the position in the original input.
+	for _, tt := range []struct {
+		codec  enc.Codec
+		in     string
+		pos    int
+		char   rune
+		reason string
+	}{
+		{enc.Crockford32, "csqp-yrk1-eU", 11, 'U', ""},
+		{enc.Crockford32, "CSQP\nYRK1\nE8=", 12, '=', ""},
+		{enc.Crockford32, "CSQP-YRK1-E9", 11, '9', "non-canonical last character"},
+		{enc.Crockford32, "CSQ", 2, 'Q', "non-canonical last character"},
+		{enc.ZBase32, "6n9hl", 4, 'l', ""},
+		{enc.ZBase32, "6n9hb", 4, 'b', "non-canonical last character"},
+	} {
+		_, err := io.ReadAll(tt.codec.NewDecoder(iotest.OneByteReader(strings.NewReader(tt.in))))
+		var ie *enc.InvalidCharError
+		assert.True(t, errors.As(err, &ie), "%s: %v", tt.in, err)
+		assert.EqualValues(t, tt.pos, ie.Pos, tt.in)
+		assert.EqualValues(t, tt.char, ie.Char, tt.in)
+		assert.EqualValues(t, tt.reason, ie.Reason, tt.in)
+
+		// same as DecodeString.
+		_, err = tt.codec.DecodeString(tt.in)
+		assert.EqualValues(t, err, ie, tt.in)
+	}
+}
+
+func TestCrockford32_Lenient(t *testing.T) {
+	out, err := enc.Crockford32.DecodeString("csqp-yrk1-e8")
+	assert.NoError(t, err)
+	assert.EqualValues(t, "foobar", string(out))
+
+	// I, L and O are read as 1, 1 and 0.
+	a, err := enc.Crockford32.DecodeString("01AB01AB")
+	assert.NoError(t, err)
+	b, err := enc.Crockford32.DecodeString("olabOLAB")
+	assert.NoError(t, err)
+	assert.EqualValues(t, a, b)
+}
+
+func TestCrockfordCheck(t *testing.T) {
+	for _, in := range [][]byte{{}, {1}, []byte("foobar"), {36}, {0xff, 0xff}} {
+		s := enc.EncodeCrockfordCheck(in)
+		out, err := enc.DecodeCrockfordCheck(s)
+		assert.NoError(t, err)
+		assert.True(t, bytes.Equal(in, out))
+
+		out, err = enc.DecodeCrockfordCheck(strings.ToLower(s))
+		assert.NoError(t, err)
+		assert.True(t, bytes.Equal(in, out))
+	}
+	// 36 mod 37 is 'U'.
+	assert.EqualValues(t, "4GU", enc.EncodeCrockfordCheck([]byte{36}))
+
+	s := enc.EncodeCrockfordCheck([]byte("foobar"))
+	_, err := enc.DecodeCrockfordCheck(s[:len(s)-1] + "0")
+	assert.True(t, errors.Is(err, enc.ErrChecksum))
+	_, err = enc.DecodeCrockfordCheck(s[:len(s)-1] + "#")
+	assert.Error(t, err)
+	_, err = enc.DecodeCrockfordCheck("")
+	assert.Error(t, err)
+}
+
+func BenchmarkBase58(b *testing.B) {
+	data := bytes.Repeat([]byte{0xa5}, 32)
+	for i := 0; i < b.N; i++ {
+		_, _ = enc.Base58.DecodeString(enc.Base58.EncodeToString(data))
+	}
+}
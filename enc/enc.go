@@ -3,19 +3,19 @@ package enc
 import (
 	"crypto/sha256"
 	"fmt"
-	"hash/fnv"
 )
 
 // Hash32 converts string to hash value.
+//
+// Deprecated: the error is always nil. Use SumString(FNV32a, s).Uint32() instead.
 func Hash32(s string) (uint32, error) {
-	h := fnv.New32a()
-	if _, err := h.Write([]byte(s)); err != nil {
-		return 0, err
-	}
-	return h.Sum32(), nil
+	return SumString(FNV32a, s).Uint32(), nil
 }
 
 // Encrypt256Password returns SHA256 encrypted string.
+//
+// Deprecated: unsalted single SHA-256 is not suitable for storing passwords. Use HashPassword instead.
+// VerifyPassword accepts digests generated by this function so that they can be migrated.
 func Encrypt256Password(src string) string {
 	return fmt.Sprintf("%x", sha256.Sum256([]byte(src)))
 }
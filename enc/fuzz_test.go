@@ -0,0 +1,317 @@
+package enc_test
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/marrbor/goutil/enc"
+)
+
+// fuzzKeys are fixed keys for fuzzing, one for each AEAD algorithm.
+var fuzzKeys = []*enc.Key{
+	{ID: "aes", Algorithm: enc.AES256GCM, Secret: bytes.Repeat([]byte{1}, enc.KeySize)},
+	{ID: "chacha", Algorithm: enc.ChaCha20Poly1305, Secret: bytes.Repeat([]byte{2}, enc.KeySize)},
+}
+
+// fuzzCodec verifies round trip of given codec and that decoding arbitrary string does not panic.
+func fuzzCodec(f *testing.F, c enc.Codec) {
+	f.Add([]byte{}, "")
+	f.Add([]byte{0, 0, 1}, "11")
+	f.Add([]byte("goutil"), "2NEpo7TZRRrLZSi2U")
+	f.Add(bytes.Repeat([]byte{0xff}, 33), "6n9hq-0O!")
+	f.Fuzz(func(t *testing.T, data []byte, s string) {
+		got, err := c.DecodeString(c.EncodeToString(data))
+		if err != nil || !bytes.Equal(got, data) {
+			t.Fatalf("round trip of %x: %x, %v", data, got, err)
+		}
+
+		d, err := c.DecodeString(s)
+		if err != nil {
+			return
+		}
+		// accepted string has to decode to the same data after re-encoding.
+		again, err := c.DecodeString(c.EncodeToString(d))
+		if err != nil || !bytes.Equal(again, d) {
+			t.Fatalf("re-encoding of %q: %x, %v", s, again, err)
+		}
+	})
+}
+
+func FuzzBase58(f *testing.F)      { fuzzCodec(f, enc.Base58) }
+func FuzzBase62(f *testing.F)      { fuzzCodec(f, enc.Base62) }
+func FuzzCrockford32(f *testing.F) { fuzzCodec(f, enc.Crockford32) }
+func FuzzZBase32(f *testing.F)     { fuzzCodec(f, enc.ZBase32) }
+
+func FuzzCrockfordCheck(f *testing.F) {
+	f.Add([]byte("foobar"), uint(0))
+	f.Add([]byte{}, uint(3))
+	f.Fuzz(func(t *testing.T, data []byte, i uint) {
+		s := enc.EncodeCrockfordCheck(data)
+		got, err := enc.DecodeCrockfordCheck(s)
+		if err != nil || !bytes.Equal(got, data) {
+			t.Fatalf("round trip of %x: %x, %v", data, got, err)
+		}
+		// changing the check symbol is always detected.
+		const symbols = "0123456789ABCDEFGHJKMNPQRSTVWXYZ*~$=U"
+		last := strings.IndexByte(symbols, s[len(s)-1])
+		tampered := s[:len(s)-1] + string(symbols[(last+1+int(i%36))%37])
+		if _, err := enc.DecodeCrockfordCheck(tampered); err == nil {
+			t.Fatalf("tampered %q accepted", tampered)
+		}
+	})
+}
+
+func FuzzSealOpen(f *testing.F) {
+	f.Add([]byte("plaintext"), []byte("ad"), uint(0))
+	f.Add([]byte{}, []byte{}, uint(100))
+	f.Fuzz(func(t *testing.T, pt, ad []byte, i uint) {
+		for _, k := range fuzzKeys {
+			env, err := enc.Seal(k, pt, ad)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := enc.Open(k, env, ad)
+			if err != nil || !bytes.Equal(got, pt) {
+				t.Fatalf("round trip: %x, %v", got, err)
+			}
+			// every single bit flip is detected.
+			env[i%uint(len(env))] ^= 1 << (i % 8)
+			if _, err := enc.Open(k, env, ad); err == nil {
+				t.Fatalf("tampered envelope at %d accepted", i%uint(len(env)))
+			}
+		}
+	})
+}
+
+func FuzzOpen(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{1, 1, 3, 'a', 'e', 's'})
+	f.Add(append([]byte{1, 2, 6, 'c', 'h', 'a', 'c', 'h', 'a'}, make([]byte, 40)...))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, k := range fuzzKeys {
+			if _, err := enc.Open(k, data, nil); err == nil {
+				t.Fatalf("forged envelope %x accepted", data)
+			}
+		}
+		s := string(data)
+		if _, err := enc.OpenString(fuzzKeys[0], s, nil); err == nil {
+			t.Fatalf("forged envelope %q accepted", s)
+		}
+	})
+}
+
+func FuzzSealString(f *testing.F) {
+	f.Add([]byte("plaintext"), uint(5))
+	f.Fuzz(func(t *testing.T, pt []byte, i uint) {
+		k := fuzzKeys[i%uint(len(fuzzKeys))]
+		s, err := enc.SealString(k, pt, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := enc.OpenString(k, s, nil)
+		if err != nil || !bytes.Equal(got, pt) {
+			t.Fatalf("round trip: %x, %v", got, err)
+		}
+		if _, err := enc.OpenString(k, s, []byte("other")); err == nil {
+			t.Fatal("other associated data accepted")
+		}
+	})
+}
+
+func FuzzStream(f *testing.F) {
+	f.Add([]byte("plaintext"), uint(3), uint(0))
+	f.Add([]byte{}, uint(1), uint(1))
+	f.Fuzz(func(t *testing.T, pt []byte, chunk, cut uint) {
+		for _, k := range fuzzKeys {
+			var buf bytes.Buffer
+			w, err := enc.NewSealWriter(&buf, k, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// write in chunks of given size.
+			size := int(chunk%64) + 1
+			for p := pt; len(p) > 0; {
+				n := min(size, len(p))
+				if _, err := w.Write(p[:n]); err != nil {
+					t.Fatal(err)
+				}
+				p = p[n:]
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+			sealed := buf.Bytes()
+
+			r, err := enc.NewOpenReader(bytes.NewReader(sealed), k, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := io.ReadAll(r)
+			if err != nil || !bytes.Equal(got, pt) {
+				t.Fatalf("round trip: %x, %v", got, err)
+			}
+
+			// truncated stream is rejected.
+			short := sealed[:cut%uint(len(sealed))]
+			r, err = enc.NewOpenReader(bytes.NewReader(short), k, nil)
+			if err == nil {
+				_, err = io.ReadAll(r)
+			}
+			if err == nil {
+				t.Fatalf("truncated stream of %d bytes accepted", len(short))
+			}
+		}
+	})
+}
+
+func FuzzSigner(f *testing.F) {
+	f.Add([]byte("payload"), "", uint(0))
+	f.Add([]byte{}, "a.b.c", uint(7))
+	now := time.Unix(1700000000, 0)
+	s := &enc.Signer{Key: []byte("secret"), Now: func() time.Time { return now }}
+	f.Fuzz(func(t *testing.T, payload []byte, forged string, i uint) {
+		token := s.Sign(payload, time.Minute)
+		got, err := s.Verify(token)
+		if err != nil || !bytes.Equal(got, payload) {
+			t.Fatalf("round trip: %x, %v", got, err)
+		}
+		b := []byte(token)
+		b[i%uint(len(b))] ^= 1
+		if got, err := s.Verify(string(b)); err == nil && !bytes.Equal(got, payload) {
+			t.Fatalf("tampered token %q accepted", b)
+		}
+		if forged != token {
+			if _, err := s.Verify(forged); err == nil {
+				t.Fatalf("forged token %q accepted", forged)
+			}
+		}
+	})
+}
+
+func FuzzJWT(f *testing.F) {
+	f.Add("user", uint(0))
+	f.Add("", uint(42))
+	key := []byte("jwt secret")
+	signer := &enc.JWTSigner{Algorithm: enc.HS256, Key: key}
+	verifier := &enc.JWTVerifier{Algorithm: enc.HS256, Key: key}
+	f.Fuzz(func(t *testing.T, sub string, i uint) {
+		if !utf8.ValidString(sub) {
+			return // encoding/json replaces invalid bytes.
+		}
+		token, err := signer.Sign(enc.Claims{Subject: sub})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var c enc.Claims
+		if err := verifier.Verify(token, &c); err != nil || c.Subject != sub {
+			t.Fatalf("round trip: %q, %v", c.Subject, err)
+		}
+		b := []byte(token)
+		b[i%uint(len(b))] ^= 1
+		if err := verifier.Verify(string(b), &c); err == nil {
+			// flipping the low bit of the last base64url character may keep the decoded signature.
+			if i%uint(len(b)) != uint(len(b)-1) {
+				t.Fatalf("tampered token %q accepted", b)
+			}
+		}
+	})
+}
+
+func FuzzReadVectors(f *testing.F) {
+	f.Add("[L = 32]\nMsg = 00\n\nMsg = \"a\"\n")
+	f.Add("# comment\n[open\n")
+	f.Fuzz(func(t *testing.T, src string) {
+		vs, err := enc.ReadVectors(strings.NewReader(src))
+		if err != nil {
+			return
+		}
+		for _, v := range vs {
+			if len(v.Fields) == 0 || v.Line <= 0 {
+				t.Fatalf("empty vector %+v", v)
+			}
+		}
+	})
+}
+
+// cheapPHC returns whether every cost parameter of given PHC string is small enough to fuzz quickly.
+func cheapPHC(encoded string) bool {
+	for _, field := range strings.Split(encoded, "$") {
+		if !strings.Contains(field, "=") || strings.HasPrefix(field, "v=") {
+			continue
+		}
+		notDigit := func(r rune) bool { return r < '0' || '9' < r }
+		for _, d := range strings.FieldsFunc(field, notDigit) {
+			if n, err := strconv.Atoi(d); err != nil || n > 16 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func FuzzVerifyPassword(f *testing.F) {
+	for _, p := range []enc.PasswordParams{
+		{Algorithm: enc.PBKDF2SHA256, Iterations: 1, SaltLen: 8, KeyLen: 16},
+		{Algorithm: enc.Scrypt, LogN: 2, BlockSize: 1, Parallelism: 1, SaltLen: 8, KeyLen: 16},
+		{Algorithm: enc.Argon2id, Iterations: 1, Memory: 8, Parallelism: 1, SaltLen: 8, KeyLen: 16},
+	} {
+		h, err := enc.HashPasswordWith("password", p)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add("password", h)
+	}
+	f.Add("x", "$scrypt$ln=4,r=8,p=0$c2FsdA$a2V5")
+	f.Add("x", "$pbkdf2-sha256$i=0$c2FsdA$a2V5")
+	f.Add("x", enc.Encrypt256Password("x"))
+	f.Fuzz(func(t *testing.T, password, encoded string) {
+		if !cheapPHC(encoded) {
+			return
+		}
+		ok, err := enc.VerifyPassword(password, encoded)
+		if ok && err != nil {
+			t.Fatalf("verified with error: %v", err)
+		}
+		if err != nil && !enc.NeedsRehash(encoded, enc.DefaultPasswordParams) {
+			t.Fatalf("invalid hash %q does not need rehash", encoded)
+		}
+	})
+}
+
+func FuzzParseKeyRing(f *testing.F) {
+	r, err := enc.NewKeyRing(fuzzKeys...)
+	if err != nil {
+		f.Fatal(err)
+	}
+	b, err := r.MarshalJSON()
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(b)
+	f.Add([]byte(`{"keys":[{"id":"a","algorithm":"AES-256-GCM","secret":"!"}]}`))
+	f.Add([]byte(`{"primary":"x","keys":[]}`))
+	f.Fuzz(func(t *testing.T, b []byte) {
+		r, err := enc.ParseKeyRing(b)
+		if err != nil {
+			return
+		}
+		// parsed key ring has to survive round trip.
+		out, err := r.MarshalJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		again, err := enc.ParseKeyRing(out)
+		if err != nil {
+			t.Fatalf("re-parsing %s: %v", out, err)
+		}
+		out2, err := again.MarshalJSON()
+		if err != nil || !bytes.Equal(out, out2) {
+			t.Fatalf("round trip of %s: %s, %v", out, out2, err)
+		}
+	})
+}
@@ -0,0 +1,148 @@
+package enc
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"hash/crc64"
+	"hash/fnv"
+	"io"
+)
+
+// HashAlgorithm is a hash function available through Sum, SumString and SumReader.
+type HashAlgorithm int
+
+const (
+	FNV32a HashAlgorithm = iota + 1
+	FNV64a
+	FNV128a
+	CRC32 // IEEE polynomial.
+	CRC64 // ECMA polynomial.
+	SHA1  // for compatibility only, not for security.
+	SHA256
+	SHA512
+	XXH64 // xxHash64 with seed 0, fast non-cryptographic hash.
+)
+
+var crc64Table = crc64.MakeTable(crc64.ECMA)
+
+// hashAlgorithms holds name and constructor of each algorithm.
+var hashAlgorithms = map[HashAlgorithm]struct {
+	name string
+	new  func() hash.Hash
+}{
+	FNV32a:  {"fnv32a", func() hash.Hash { return fnv.New32a() }},
+	FNV64a:  {"fnv64a", func() hash.Hash { return fnv.New64a() }},
+	FNV128a: {"fnv128a", fnv.New128a},
+	CRC32:   {"crc32", func() hash.Hash { return crc32.NewIEEE() }},
+	CRC64:   {"crc64", func() hash.Hash { return crc64.New(crc64Table) }},
+	SHA1:    {"sha1", sha1.New},
+	SHA256:  {"sha256", sha256.New},
+	SHA512:  {"sha512", sha512.New},
+	XXH64:   {"xxh64", func() hash.Hash { return newXXH64() }},
+}
+
+// String returns the name of the algorithm.
+func (a HashAlgorithm) String() string {
+	if h, ok := hashAlgorithms[a]; ok {
+		return h.name
+	}
+	return fmt.Sprintf("HashAlgorithm(%d)", int(a))
+}
+
+// ParseHashAlgorithm returns the algorithm that has given name.
+func ParseHashAlgorithm(name string) (HashAlgorithm, error) {
+	for a, h := range hashAlgorithms {
+		if h.name == name {
+			return a, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %s", ErrUnknownAlgorithm, name)
+}
+
+// New returns a new hash.Hash of the algorithm. It panics for unknown algorithm like crypto.Hash.New.
+func (a HashAlgorithm) New() hash.Hash {
+	h, ok := hashAlgorithms[a]
+	if !ok {
+		panic("enc: unknown hash algorithm " + a.String())
+	}
+	return h.new()
+}
+
+// Available returns whether the algorithm is known or not.
+func (a HashAlgorithm) Available() bool {
+	_, ok := hashAlgorithms[a]
+	return ok
+}
+
+// Digest is a hash value in big endian byte order.
+type Digest []byte
+
+// Hex returns lower case hex string of the digest.
+func (d Digest) Hex() string {
+	return hex.EncodeToString(d)
+}
+
+// Base64 returns padded standard base64 string of the digest.
+func (d Digest) Base64() string {
+	return base64.StdEncoding.EncodeToString(d)
+}
+
+// Base64URL returns unpadded base64url string of the digest.
+func (d Digest) Base64URL() string {
+	return b64url.EncodeToString(d)
+}
+
+// String returns hex string of the digest.
+func (d Digest) String() string {
+	return d.Hex()
+}
+
+// Uint32 returns the last 4 bytes of the digest as an integer, which is the value of 32 bit hashes.
+func (d Digest) Uint32() uint32 {
+	if len(d) < 4 {
+		return 0
+	}
+	return binary.BigEndian.Uint32(d[len(d)-4:])
+}
+
+// Uint64 returns the last 8 bytes of the digest as an integer, which is the value of 64 bit hashes.
+func (d Digest) Uint64() uint64 {
+	if len(d) < 8 {
+		return uint64(d.Uint32())
+	}
+	return binary.BigEndian.Uint64(d[len(d)-8:])
+}
+
+// Sum returns the digest of given data. It panics for unknown algorithm like HashAlgorithm.New, so check an algorithm
+// from configuration by ParseHashAlgorithm or Available first, or use SumReader.
+func Sum(alg HashAlgorithm, b []byte) Digest {
+	h := alg.New()
+	h.Write(b)
+	return h.Sum(nil)
+}
+
+// SumString returns the digest of given string. It panics for unknown algorithm like Sum.
+func SumString(alg HashAlgorithm, s string) Digest {
+	h := alg.New()
+	_, _ = io.WriteString(h, s)
+	return h.Sum(nil)
+}
+
+// SumReader returns the digest of all data read from given reader, or ErrUnknownAlgorithm for unknown algorithm.
+func SumReader(alg HashAlgorithm, r io.Reader) (Digest, error) {
+	if !alg.Available() {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownAlgorithm, alg)
+	}
+	h := alg.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
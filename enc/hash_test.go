@@ -0,0 +1,103 @@
+package enc_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/marrbor/goutil/enc"
+	"github.com/stretchr/testify/assert"
+)
+
+var hashAlgorithms = []enc.HashAlgorithm{
+	enc.FNV32a, enc.FNV64a, enc.FNV128a, enc.CRC32, enc.CRC64, enc.SHA1, enc.SHA256, enc.SHA512, enc.XXH64,
+}
+
+func TestSum(t *testing.T) {
+	expects := map[enc.HashAlgorithm]string{
+		enc.FNV32a: "2a9eb737",
+		enc.FNV64a: "af63dc4c8601ec8c",
+		enc.CRC32:  "352441c2",
+		enc.SHA1:   "a9993e364706816aba3e25717850c26c9cd0d89d",
+		enc.SHA256: "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+		enc.XXH64:  "44bc2cf5ad770999",
+	}
+	for alg, want := range expects {
+		in := "abc"
+		switch alg {
+		case enc.FNV32a:
+			in = "abcdefg"
+		case enc.FNV64a:
+			in = "a"
+		}
+		assert.EqualValues(t, want, enc.SumString(alg, in).Hex(), alg.String())
+	}
+}
+
+func TestSum_Forms(t *testing.T) {
+	for _, alg := range hashAlgorithms {
+		data := strings.Repeat("goutil", 100)
+		d := enc.Sum(alg, []byte(data))
+		assert.EqualValues(t, d, enc.SumString(alg, data), alg.String())
+		r, err := enc.SumReader(alg, strings.NewReader(data))
+		assert.NoError(t, err)
+		assert.EqualValues(t, d, r, alg.String())
+		assert.EqualValues(t, alg.New().Size(), len(d))
+
+		p, err := enc.ParseHashAlgorithm(alg.String())
+		assert.NoError(t, err)
+		assert.EqualValues(t, alg, p)
+		assert.True(t, alg.Available())
+	}
+
+	d := enc.SumString(enc.SHA256, "abc")
+	assert.EqualValues(t, "ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0=", d.Base64())
+	assert.EqualValues(t, "ungWv48Bz-pBQUDeXa4iI7ADYaOWF3qctBD_YfIAFa0", d.Base64URL())
+	assert.EqualValues(t, d.Hex(), d.String())
+
+	assert.EqualValues(t, uint32(0x2a9eb737), enc.SumString(enc.FNV32a, "abcdefg").Uint32())
+	assert.EqualValues(t, uint64(0x44bc2cf5ad770999), enc.SumString(enc.XXH64, "abc").Uint64())
+	assert.EqualValues(t, uint64(0x2a9eb737), enc.SumString(enc.FNV32a, "abcdefg").Uint64())
+	assert.EqualValues(t, 0, enc.Digest{1}.Uint32())
+
+	_, err := enc.ParseHashAlgorithm("md5")
+	assert.Error(t, err)
+	assert.False(t, enc.HashAlgorithm(0).Available())
+	assert.Panics(t, func() { enc.HashAlgorithm(0).New() })
+	assert.Panics(t, func() { enc.Sum(enc.HashAlgorithm(0), nil) })
+	_, err = enc.SumReader(enc.HashAlgorithm(99), strings.NewReader("abc"))
+	assert.True(t, errors.Is(err, enc.ErrUnknownAlgorithm))
+}
+
+func TestXXH64(t *testing.T) {
+	expects := map[string]uint64{
+		"":     0xef46db3751d8e999,
+		"a":    0xd24ec4f1a98c6e5b,
+		"as":   0x1c330fb2d66be179,
+		"asd":  0x631c37ce72a97393,
+		"asdf": 0x415872f599cea71e,
+		"Call me Ishmael. Some years ago--never mind how long precisely-": 0x02a2e85470d6fd96,
+	}
+	for in, want := range expects {
+		assert.EqualValues(t, want, enc.SumString(enc.XXH64, in).Uint64(), in)
+
+		// write byte by byte.
+		h := enc.XXH64.New()
+		for i := 0; i < len(in); i++ {
+			h.Write([]byte{in[i]})
+		}
+		assert.EqualValues(t, want, enc.Digest(h.Sum(nil)).Uint64(), in)
+	}
+}
+
+func BenchmarkSum(b *testing.B) {
+	data := []byte(strings.Repeat("x", 4096))
+	for _, alg := range hashAlgorithms {
+		b.Run(alg.String(), func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				enc.Sum(alg, data)
+			}
+		})
+	}
+}
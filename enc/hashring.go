@@ -0,0 +1,238 @@
+package enc
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// DefaultVirtualNodes is the number of virtual nodes per weight used when 0 is given to NewHashRing.
+const DefaultVirtualNodes = 160
+
+type (
+	// HashRing is a consistent hashing ring. Adding or removing a member moves only the keys of that member.
+	// HashRing is safe for concurrent use.
+	HashRing struct {
+		mu      sync.RWMutex
+		alg     HashAlgorithm
+		vnodes  int
+		weights map[string]int
+		points  []ringPoint // sorted by hash.
+	}
+
+	ringPoint struct {
+		hash   uint64
+		member string
+	}
+
+	// Rendezvous is a rendezvous (highest random weight) hashing. It needs no virtual nodes and distributes keys
+	// evenly at the cost of scoring every member on lookup. Rendezvous is safe for concurrent use.
+	Rendezvous struct {
+		mu      sync.RWMutex
+		alg     HashAlgorithm
+		weights map[string]int
+	}
+)
+
+// NewHashRing returns an empty ring that places members by given hash algorithm with given number of virtual
+// nodes per weight. It returns ErrUnknownAlgorithm when the algorithm is not available.
+func NewHashRing(alg HashAlgorithm, vnodes int) (*HashRing, error) {
+	if !alg.Available() {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownAlgorithm, alg)
+	}
+	if vnodes <= 0 {
+		vnodes = DefaultVirtualNodes
+	}
+	return &HashRing{alg: alg, vnodes: vnodes, weights: make(map[string]int)}, nil
+}
+
+// Add adds given member with given weight, or changes the weight of the member already added.
+// A member with weight 2 receives about twice as many keys as a member with weight 1.
+func (r *HashRing) Add(member string, weight int) {
+	if weight <= 0 {
+		weight = 1
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.weights[member] = weight
+	r.build()
+}
+
+// Remove removes given member.
+func (r *HashRing) Remove(member string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.weights, member)
+	r.build()
+}
+
+// Members returns sorted members.
+func (r *HashRing) Members() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return sortedMembers(r.weights)
+}
+
+// build rebuilds points from weights.
+func (r *HashRing) build() {
+	r.points = r.points[:0]
+	for m, w := range r.weights {
+		for i := 0; i < r.vnodes*w; i++ {
+			r.points = append(r.points, ringPoint{hash: sum64(r.alg, m+"#"+strconv.Itoa(i)), member: m})
+		}
+	}
+	// member name breaks ties to be deterministic regardless of map order.
+	sort.Slice(r.points, func(i, j int) bool {
+		if r.points[i].hash != r.points[j].hash {
+			return r.points[i].hash < r.points[j].hash
+		}
+		return r.points[i].member < r.points[j].member
+	})
+}
+
+// Lookup returns the member that owns given key. It returns false when the ring is empty.
+func (r *HashRing) Lookup(key string) (string, bool) {
+	m := r.LookupN(key, 1)
+	if len(m) == 0 {
+		return "", false
+	}
+	return m[0], true
+}
+
+// LookupN returns up to n distinct members for given key in preference order, which is used for replicas.
+func (r *HashRing) LookupN(key string, n int) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if n > len(r.weights) {
+		n = len(r.weights)
+	}
+	if n <= 0 {
+		return nil
+	}
+	h := sum64(r.alg, key)
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i].hash >= h })
+
+	ret := make([]string, 0, n)
+	for c := 0; c < len(r.points) && len(ret) < n; c++ {
+		m := r.points[(i+c)%len(r.points)].member
+		if !contains(ret, m) {
+			ret = append(ret, m)
+		}
+	}
+	return ret
+}
+
+// NewRendezvous returns an empty rendezvous hashing that scores members by given hash algorithm. It returns
+// ErrUnknownAlgorithm when the algorithm is not available.
+func NewRendezvous(alg HashAlgorithm) (*Rendezvous, error) {
+	if !alg.Available() {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownAlgorithm, alg)
+	}
+	return &Rendezvous{alg: alg, weights: make(map[string]int)}, nil
+}
+
+// Add adds given member with given weight, or changes the weight of the member already added.
+func (r *Rendezvous) Add(member string, weight int) {
+	if weight <= 0 {
+		weight = 1
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.weights[member] = weight
+}
+
+// Remove removes given member.
+func (r *Rendezvous) Remove(member string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.weights, member)
+}
+
+// Members returns sorted members.
+func (r *Rendezvous) Members() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return sortedMembers(r.weights)
+}
+
+// score returns weighted score of given member for given key. refer: https://en.wikipedia.org/wiki/Rendezvous_hashing
+func (r *Rendezvous) score(member, key string, weight int) float64 {
+	// map hash to (0, 1) then to weighted score.
+	u := (float64(sum64(r.alg, member+"\x00"+key)>>11) + 0.5) / (1 << 53)
+	return -float64(weight) / math.Log(u)
+}
+
+// Lookup returns the member that owns given key. It returns false when no member is added.
+func (r *Rendezvous) Lookup(key string) (string, bool) {
+	m := r.LookupN(key, 1)
+	if len(m) == 0 {
+		return "", false
+	}
+	return m[0], true
+}
+
+// LookupN returns up to n distinct members for given key in preference order.
+func (r *Rendezvous) LookupN(key string, n int) []string {
+	r.mu.RLock()
+	type scored struct {
+		member string
+		score  float64
+	}
+	list := make([]scored, 0, len(r.weights))
+	for m, w := range r.weights {
+		list = append(list, scored{member: m, score: r.score(m, key, w)})
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].score != list[j].score {
+			return list[i].score > list[j].score
+		}
+		return list[i].member < list[j].member
+	})
+	if n > len(list) {
+		n = len(list)
+	}
+	if n <= 0 {
+		return nil
+	}
+	ret := make([]string, n)
+	for i := range ret {
+		ret[i] = list[i].member
+	}
+	return ret
+}
+
+// sortedMembers returns sorted keys of given map.
+func sortedMembers(weights map[string]int) []string {
+	ret := make([]string, 0, len(weights))
+	for m := range weights {
+		ret = append(ret, m)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// contains returns whether given list has given string or not.
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// sum64 returns 64 bit hash of given string by given algorithm. The digest is mixed by the finalizer of splitmix64,
+// so that 32 bit hashes and hashes with weak avalanche like FNV spread over the whole 64 bit range.
+func sum64(alg HashAlgorithm, s string) uint64 {
+	x := SumString(alg, s).Uint64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
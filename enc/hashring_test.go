@@ -0,0 +1,170 @@
+package enc_test
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/marrbor/goutil/enc"
+	"github.com/stretchr/testify/assert"
+)
+
+// balancer is the common interface of HashRing and Rendezvous.
+type balancer interface {
+	Add(member string, weight int)
+	Remove(member string)
+	Members() []string
+	Lookup(key string) (string, bool)
+	LookupN(key string, n int) []string
+}
+
+var balancers = map[string]func() balancer{
+	"ring":              func() balancer { return newRing(enc.XXH64) },
+	"ring/fnv32a":       func() balancer { return newRing(enc.FNV32a) },
+	"ring/crc32":        func() balancer { return newRing(enc.CRC32) },
+	"rendezvous":        func() balancer { return newRendezvous(enc.XXH64) },
+	"rendezvous/fnv32a": func() balancer { return newRendezvous(enc.FNV32a) },
+	"rendezvous/fnv64a": func() balancer { return newRendezvous(enc.FNV64a) },
+	"rendezvous/crc32":  func() balancer { return newRendezvous(enc.CRC32) },
+}
+
+// newRing returns HashRing with default virtual nodes, panics on error.
+func newRing(alg enc.HashAlgorithm) *enc.HashRing {
+	r, err := enc.NewHashRing(alg, 0)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// newRendezvous returns Rendezvous, panics on error.
+func newRendezvous(alg enc.HashAlgorithm) *enc.Rendezvous {
+	r, err := enc.NewRendezvous(alg)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// assign returns owner of each key.
+func assign(b balancer, keys int) map[string]string {
+	ret := make(map[string]string)
+	for i := 0; i < keys; i++ {
+		k := "key" + strconv.Itoa(i)
+		m, _ := b.Lookup(k)
+		ret[k] = m
+	}
+	return ret
+}
+
+func TestBalancer_Empty(t *testing.T) {
+	for name, f := range balancers {
+		b := f()
+		_, ok := b.Lookup("x")
+		assert.False(t, ok, name)
+		assert.Nil(t, b.LookupN("x", 3), name)
+	}
+}
+
+func TestBalancer_Deterministic(t *testing.T) {
+	for name, f := range balancers {
+		b1, b2 := f(), f()
+		for i := 0; i < 5; i++ {
+			b1.Add(fmt.Sprintf("node%d", i), 1)
+			b2.Add(fmt.Sprintf("node%d", 4-i), 1)
+		}
+		assert.EqualValues(t, assign(b1, 1000), assign(b2, 1000), name)
+		assert.EqualValues(t, []string{"node0", "node1", "node2", "node3", "node4"}, b1.Members())
+	}
+}
+
+func TestBalancer_Distribution(t *testing.T) {
+	for name, f := range balancers {
+		b := f()
+		b.Add("a", 1)
+		b.Add("b", 1)
+		b.Add("c", 2)
+		count := map[string]int{}
+		for _, m := range assign(b, 20000) {
+			count[m]++
+		}
+		// c has about a half, a and b have about a quarter each.
+		assert.InDelta(t, 10000, count["c"], 1000, name)
+		assert.InDelta(t, 5000, count["a"], 800, name)
+		assert.InDelta(t, 5000, count["b"], 800, name)
+	}
+}
+
+func TestBalancer_MinimalDisruption(t *testing.T) {
+	for name, f := range balancers {
+		b := f()
+		for i := 0; i < 4; i++ {
+			b.Add(fmt.Sprintf("node%d", i), 1)
+		}
+		before := assign(b, 10000)
+
+		// adding a member moves keys only to the new member.
+		b.Add("node4", 1)
+		after := assign(b, 10000)
+		moved := 0
+		for k, m := range after {
+			if m != before[k] {
+				moved++
+				assert.EqualValues(t, "node4", m, name)
+			}
+		}
+		assert.InDelta(t, 2000, moved, 500, name)
+
+		// removing it restores previous assignment.
+		b.Remove("node4")
+		assert.EqualValues(t, before, assign(b, 10000), name)
+	}
+}
+
+func TestBalancer_LookupN(t *testing.T) {
+	for name, f := range balancers {
+		b := f()
+		b.Add("a", 1)
+		b.Add("b", 1)
+		b.Add("c", 1)
+		for i := 0; i < 100; i++ {
+			k := "key" + strconv.Itoa(i)
+			l := b.LookupN(k, 2)
+			assert.EqualValues(t, 2, len(l), name)
+			assert.NotEqual(t, l[0], l[1], name)
+			first, _ := b.Lookup(k)
+			assert.EqualValues(t, first, l[0], name)
+		}
+		assert.EqualValues(t, 3, len(b.LookupN("x", 5)), name)
+	}
+}
+
+func TestNewHashRing_UnknownAlgorithm(t *testing.T) {
+	_, err := enc.NewHashRing(enc.HashAlgorithm(255), 0)
+	assert.True(t, errors.Is(err, enc.ErrUnknownAlgorithm))
+	_, err = enc.NewRendezvous(enc.HashAlgorithm(255))
+	assert.True(t, errors.Is(err, enc.ErrUnknownAlgorithm))
+}
+
+func BenchmarkHashRing_Lookup(b *testing.B) {
+	r := newRing(enc.XXH64)
+	for i := 0; i < 16; i++ {
+		r.Add(fmt.Sprintf("node%d", i), 1)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.Lookup("key" + strconv.Itoa(i))
+	}
+}
+
+func BenchmarkRendezvous_Lookup(b *testing.B) {
+	r := newRendezvous(enc.XXH64)
+	for i := 0; i < 16; i++ {
+		r.Add(fmt.Sprintf("node%d", i), 1)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.Lookup("key" + strconv.Itoa(i))
+	}
+}
@@ -0,0 +1,172 @@
+package enc
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HMACAlgorithm is a hash function used for HMAC.
+type HMACAlgorithm int
+
+const (
+	HMACSHA256 HMACAlgorithm = iota
+	HMACSHA512
+)
+
+var (
+	ErrInvalidToken     = errors.New("invalid token")
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrExpired          = errors.New("token expired")
+)
+
+// newHash returns hash constructor of the algorithm.
+func (a HMACAlgorithm) newHash() func() hash.Hash {
+	if a == HMACSHA512 {
+		return sha512.New
+	}
+	return sha256.New
+}
+
+// SignHMAC returns HMAC of given message with given key.
+func SignHMAC(alg HMACAlgorithm, key, msg []byte) []byte {
+	h := hmac.New(alg.newHash(), key)
+	h.Write(msg)
+	return h.Sum(nil)
+}
+
+// VerifyHMAC returns whether given signature is HMAC of given message with given key or not.
+// The comparison takes constant time.
+func VerifyHMAC(alg HMACAlgorithm, key, msg, sig []byte) bool {
+	return hmac.Equal(SignHMAC(alg, key, msg), sig)
+}
+
+// Signer generates and verifies signed values. A signed value is URL-safe string like:
+//
+//	<base64url payload>.<expiry unix time>.<base64url signature>
+//
+// Payload is not encrypted; use Seal to hide it.
+type Signer struct {
+	Algorithm HMACAlgorithm
+	Key       []byte
+	Skew      time.Duration    // allowed clock difference between signer and verifier.
+	Now       func() time.Time // returns current time, time.Now is used when nil.
+}
+
+// now returns current time.
+func (s *Signer) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
+
+// Sign returns signed value of given payload that expires after given duration. It never expires when ttl is 0.
+func (s *Signer) Sign(payload []byte, ttl time.Duration) string {
+	body := signedBody(payload, expiry(s.now(), ttl))
+	return body + "." + b64url.EncodeToString(SignHMAC(s.Algorithm, s.Key, []byte(body)))
+}
+
+// Verify verifies given signed value and returns its payload.
+func (s *Signer) Verify(token string) ([]byte, error) {
+	body, sig, err := cutSignature(token)
+	if err != nil {
+		return nil, err
+	}
+	if !VerifyHMAC(s.Algorithm, s.Key, []byte(body), sig) {
+		return nil, ErrInvalidSignature
+	}
+	return parseSignedBody(body, s.now(), s.Skew)
+}
+
+// SignValue returns signed value of given payload with the primary key. Key id is stored in the value to select
+// the key on verification:
+//
+//	<base64url payload>.<expiry unix time>.<base64url key id>.<base64url signature>
+func (r *KeyRing) SignValue(payload []byte, ttl time.Duration) (string, error) {
+	k, err := r.Primary()
+	if err != nil {
+		return "", err
+	}
+	body := signedBody(payload, expiry(time.Now(), ttl)) + "." + b64url.EncodeToString([]byte(k.ID))
+	mac, err := macWith(k, []byte(body))
+	if err != nil {
+		return "", err
+	}
+	return body + "." + b64url.EncodeToString(mac), nil
+}
+
+// VerifyValue verifies given signed value generated by SignValue and returns its payload.
+func (r *KeyRing) VerifyValue(token string, skew time.Duration) ([]byte, error) {
+	body, sig, err := cutSignature(token)
+	if err != nil {
+		return nil, err
+	}
+	i := strings.LastIndexByte(body, '.')
+	if i < 0 {
+		return nil, ErrInvalidToken
+	}
+	id, err := b64url.DecodeString(body[i+1:])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	if err := r.VerifyMAC(string(id), []byte(body), sig); err != nil {
+		if errors.Is(err, ErrInvalidMAC) {
+			return nil, ErrInvalidSignature
+		}
+		return nil, err
+	}
+	return parseSignedBody(body[:i], time.Now(), skew)
+}
+
+// expiry returns expiry unix time, 0 for no expiry.
+func expiry(now time.Time, ttl time.Duration) int64 {
+	if ttl == 0 {
+		return 0
+	}
+	return now.Add(ttl).Unix()
+}
+
+// signedBody returns signed part of signed value.
+func signedBody(payload []byte, exp int64) string {
+	return b64url.EncodeToString(payload) + "." + strconv.FormatInt(exp, 10)
+}
+
+// cutSignature splits given signed value into signed part and decoded signature.
+func cutSignature(token string) (string, []byte, error) {
+	i := strings.LastIndexByte(token, '.')
+	if i < 0 {
+		return "", nil, ErrInvalidToken
+	}
+	sig, err := b64url.DecodeString(token[i+1:])
+	if err != nil {
+		return "", nil, ErrInvalidToken
+	}
+	return token[:i], sig, nil
+}
+
+// parseSignedBody returns payload of verified signed part if it has not expired.
+func parseSignedBody(body string, now time.Time, skew time.Duration) ([]byte, error) {
+	p, e, ok := strings.Cut(body, ".")
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	exp, err := strconv.ParseInt(e, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	if exp != 0 && now.Add(-skew).After(time.Unix(exp, 0)) {
+		return nil, fmt.Errorf("%w at %s", ErrExpired, time.Unix(exp, 0).UTC().Format(time.RFC3339))
+	}
+	payload, err := b64url.DecodeString(p)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	return payload, nil
+}
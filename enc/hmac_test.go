@@ -0,0 +1,100 @@
+package enc_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marrbor/goutil/enc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignHMAC(t *testing.T) {
+	// RFC 4231 test case 2.
+	key := []byte("Jefe")
+	msg := []byte("what do ya want for nothing?")
+	s256 := enc.SignHMAC(enc.HMACSHA256, key, msg)
+	assert.EqualValues(t, "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843", hex.EncodeToString(s256))
+	s512 := enc.SignHMAC(enc.HMACSHA512, key, msg)
+	assert.EqualValues(t, "164b7a7bfcf819e2e395fbe73b56e0a387bd64222e831fd610270cd7ea2505549758bf75c05a994a6d034f65f8f0e6fdcaeab1a34d4a6b4b636e070a38bce737", hex.EncodeToString(s512))
+
+	assert.True(t, enc.VerifyHMAC(enc.HMACSHA256, key, msg, s256))
+	assert.False(t, enc.VerifyHMAC(enc.HMACSHA256, key, msg, s512))
+	assert.False(t, enc.VerifyHMAC(enc.HMACSHA512, []byte("jefe"), msg, s512))
+}
+
+func TestSigner(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	s := &enc.Signer{Key: []byte("secret"), Skew: 5 * time.Second, Now: func() time.Time { return now }}
+
+	token := s.Sign([]byte("/download/file.zip"), time.Minute)
+	assert.EqualValues(t, 3, len(strings.Split(token, ".")))
+	assert.False(t, strings.ContainsAny(token, "+/="))
+
+	p, err := s.Verify(token)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "/download/file.zip", string(p))
+
+	// within skew.
+	now = now.Add(time.Minute + 5*time.Second)
+	_, err = s.Verify(token)
+	assert.NoError(t, err)
+
+	// expired.
+	now = now.Add(time.Second)
+	_, err = s.Verify(token)
+	assert.True(t, errors.Is(err, enc.ErrExpired))
+
+	// tampered.
+	tampered := "L2Rvd25sb2FkL290aGVyLnppcA" + token[strings.Index(token, "."):]
+	_, err = s.Verify(tampered)
+	assert.True(t, errors.Is(err, enc.ErrInvalidSignature))
+
+	// another algorithm or key.
+	s2 := &enc.Signer{Algorithm: enc.HMACSHA512, Key: []byte("secret"), Now: s.Now}
+	_, err = s2.Verify(token)
+	assert.True(t, errors.Is(err, enc.ErrInvalidSignature))
+
+	// no expiry.
+	token = s.Sign(nil, 0)
+	now = now.Add(24 * 365 * time.Hour)
+	p, err = s.Verify(token)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, len(p))
+
+	for _, tk := range []string{"", "abc", "a.b", "a.b.!!"} {
+		_, err = s.Verify(tk)
+		assert.True(t, errors.Is(err, enc.ErrInvalidToken), tk)
+	}
+}
+
+func TestKeyRing_SignValue(t *testing.T) {
+	r, err := enc.NewKeyRing()
+	assert.NoError(t, err)
+	_, err = r.Rotate("v1", enc.AES256GCM)
+	assert.NoError(t, err)
+
+	token, err := r.SignValue([]byte("payload"), time.Hour)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 4, len(strings.Split(token, ".")))
+
+	_, err = r.Rotate("v2", enc.AES256GCM)
+	assert.NoError(t, err)
+	p, err := r.VerifyValue(token, 0)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "payload", string(p))
+
+	expired, err := r.SignValue([]byte("payload"), -time.Hour)
+	assert.NoError(t, err)
+	_, err = r.VerifyValue(expired, time.Minute)
+	assert.True(t, errors.Is(err, enc.ErrExpired))
+
+	_, err = r.VerifyValue(strings.Replace(token, "cGF5bG9hZA", "cGF5bG9hZB", 1), 0)
+	assert.True(t, errors.Is(err, enc.ErrInvalidSignature))
+
+	assert.NoError(t, r.Remove("v1"))
+	_, err = r.VerifyValue(token, 0)
+	assert.True(t, errors.Is(err, enc.ErrKeyNotFound))
+}
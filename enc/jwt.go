@@ -0,0 +1,325 @@
+package enc
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JWTAlgorithm is a JWS signing algorithm.
+type JWTAlgorithm string
+
+const (
+	HS256 JWTAlgorithm = "HS256"
+	RS256 JWTAlgorithm = "RS256"
+	ES256 JWTAlgorithm = "ES256"
+	EdDSA JWTAlgorithm = "EdDSA"
+)
+
+var (
+	ErrTokenNotYetValid  = errors.New("token not yet valid")
+	ErrInvalidClaim      = errors.New("invalid claim")
+	ErrAlgorithmMismatch = errors.New("algorithm mismatch")
+)
+
+type (
+	// NumericDate is JWT NumericDate, seconds from the epoch.
+	NumericDate int64
+
+	// Audience is JWT aud claim that is either a string or an array of strings.
+	Audience []string
+
+	// Claims is JWT registered claims. Embed it into user claims structure to be validated by JWTVerifier.
+	Claims struct {
+		Issuer    string      `json:"iss,omitempty"`
+		Subject   string      `json:"sub,omitempty"`
+		Audience  Audience    `json:"aud,omitempty"`
+		ExpiresAt NumericDate `json:"exp,omitempty"`
+		NotBefore NumericDate `json:"nbf,omitempty"`
+		IssuedAt  NumericDate `json:"iat,omitempty"`
+		ID        string      `json:"jti,omitempty"`
+	}
+
+	// JWTHeader is JOSE header of JWT.
+	JWTHeader struct {
+		Algorithm JWTAlgorithm `json:"alg"`
+		Type      string       `json:"typ,omitempty"`
+		KeyID     string       `json:"kid,omitempty"`
+		Critical  []string     `json:"crit,omitempty"`
+	}
+
+	// JWTSigner issues compact JWS tokens.
+	JWTSigner struct {
+		Algorithm JWTAlgorithm
+		// Key is []byte for HS256, *rsa.PrivateKey for RS256, *ecdsa.PrivateKey (P-256) for ES256 and
+		// ed25519.PrivateKey for EdDSA.
+		Key   interface{}
+		KeyID string // kid header, omitted when empty.
+	}
+
+	// JWTVerifier verifies compact JWS tokens. Only tokens signed with Algorithm are accepted.
+	JWTVerifier struct {
+		Algorithm JWTAlgorithm
+		// Key is []byte for HS256, *rsa.PublicKey for RS256, *ecdsa.PublicKey (P-256) for ES256 and
+		// ed25519.PublicKey for EdDSA.
+		Key interface{}
+		// KeyFunc returns Key for kid header when not nil. It is used instead of Key.
+		KeyFunc func(kid string) (interface{}, error)
+
+		Issuer     string           // required iss when not empty.
+		Audience   string           // required aud when not empty.
+		Leeway     time.Duration    // allowed clock difference on exp, nbf and iat validation.
+		RequireExp bool             // rejects tokens without exp when true.
+		Now        func() time.Time // returns current time, time.Now is used when nil.
+	}
+)
+
+// NewNumericDate returns NumericDate of given time.
+func NewNumericDate(t time.Time) NumericDate {
+	return NumericDate(t.Unix())
+}
+
+// Time returns the time of the date.
+func (d NumericDate) Time() time.Time {
+	return time.Unix(int64(d), 0)
+}
+
+// UnmarshalJSON parses JSON number that may have fraction part.
+func (d *NumericDate) UnmarshalJSON(b []byte) error {
+	f, err := strconv.ParseFloat(string(b), 64)
+	if err != nil {
+		return fmt.Errorf("%w: NumericDate %s", ErrInvalidClaim, b)
+	}
+	*d = NumericDate(f)
+	return nil
+}
+
+// MarshalJSON returns a string when the audience has just one value, otherwise an array.
+func (a Audience) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
+	}
+	return json.Marshal([]string(a))
+}
+
+// UnmarshalJSON parses a string or an array of strings.
+func (a *Audience) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*a = Audience{s}
+		return nil
+	}
+	var l []string
+	if err := json.Unmarshal(b, &l); err != nil {
+		return fmt.Errorf("%w: aud %s", ErrInvalidClaim, b)
+	}
+	*a = l
+	return nil
+}
+
+// Contains returns whether the audience has given value or not.
+func (a Audience) Contains(s string) bool {
+	for _, v := range a {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Sign returns compact JWS of given claims. Claims is a structure marshaled to JSON, typically embeds Claims.
+func (s *JWTSigner) Sign(claims interface{}) (string, error) {
+	header, err := json.Marshal(JWTHeader{Algorithm: s.Algorithm, Type: "JWT", KeyID: s.KeyID})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	input := b64url.EncodeToString(header) + "." + b64url.EncodeToString(payload)
+	sig, err := jwsSign(s.Algorithm, s.Key, []byte(input))
+	if err != nil {
+		return "", err
+	}
+	return input + "." + b64url.EncodeToString(sig), nil
+}
+
+// Verify verifies signature and registered claims of given token and unmarshals its payload into claims.
+// Tokens of other algorithms than Algorithm, including "none", are rejected.
+func (v *JWTVerifier) Verify(token string, claims interface{}) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return ErrInvalidToken
+	}
+	hb, err := b64url.DecodeString(parts[0])
+	if err != nil {
+		return ErrInvalidToken
+	}
+	var h JWTHeader
+	if err := json.Unmarshal(hb, &h); err != nil {
+		return ErrInvalidToken
+	}
+	// accept expected algorithm only not to be confused by the header written by attacker.
+	if h.Algorithm != v.Algorithm {
+		return fmt.Errorf("%w: %q", ErrAlgorithmMismatch, h.Algorithm)
+	}
+	if len(h.Critical) > 0 {
+		return fmt.Errorf("%w: unsupported crit %v", ErrInvalidToken, h.Critical)
+	}
+
+	key := v.Key
+	if v.KeyFunc != nil {
+		if key, err = v.KeyFunc(h.KeyID); err != nil {
+			return err
+		}
+	}
+	sig, err := b64url.DecodeString(parts[2])
+	if err != nil {
+		return ErrInvalidToken
+	}
+	if err := jwsVerify(v.Algorithm, key, []byte(parts[0]+"."+parts[1]), sig); err != nil {
+		return err
+	}
+
+	payload, err := b64url.DecodeString(parts[1])
+	if err != nil {
+		return ErrInvalidToken
+	}
+	var rc Claims
+	if err := json.Unmarshal(payload, &rc); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if err := v.validate(&rc); err != nil {
+		return err
+	}
+	if claims == nil {
+		return nil
+	}
+	return json.Unmarshal(payload, claims)
+}
+
+// validate validates registered claims.
+func (v *JWTVerifier) validate(c *Claims) error {
+	now := time.Now()
+	if v.Now != nil {
+		now = v.Now()
+	}
+	if c.ExpiresAt == 0 && v.RequireExp {
+		return fmt.Errorf("%w: exp required", ErrInvalidClaim)
+	}
+	if c.ExpiresAt != 0 && !now.Add(-v.Leeway).Before(c.ExpiresAt.Time()) {
+		return fmt.Errorf("%w at %s", ErrExpired, c.ExpiresAt.Time().UTC().Format(time.RFC3339))
+	}
+	if c.NotBefore != 0 && now.Add(v.Leeway).Before(c.NotBefore.Time()) {
+		return fmt.Errorf("%w until %s", ErrTokenNotYetValid, c.NotBefore.Time().UTC().Format(time.RFC3339))
+	}
+	if c.IssuedAt != 0 && now.Add(v.Leeway).Before(c.IssuedAt.Time()) {
+		return fmt.Errorf("%w: issued in the future", ErrInvalidClaim)
+	}
+	if v.Issuer != "" && c.Issuer != v.Issuer {
+		return fmt.Errorf("%w: iss %q", ErrInvalidClaim, c.Issuer)
+	}
+	if v.Audience != "" && !c.Audience.Contains(v.Audience) {
+		return fmt.Errorf("%w: aud %v", ErrInvalidClaim, []string(c.Audience))
+	}
+	return nil
+}
+
+// keyTypeError returns an error for the key that does not match the algorithm.
+func keyTypeError(alg JWTAlgorithm, key interface{}) error {
+	return fmt.Errorf("%w: %T for %s", ErrInvalidKey, key, alg)
+}
+
+// jwsSign signs given input with given algorithm and key.
+func jwsSign(alg JWTAlgorithm, key interface{}, input []byte) ([]byte, error) {
+	switch alg {
+	case HS256:
+		k, ok := key.([]byte)
+		if !ok || len(k) == 0 {
+			return nil, keyTypeError(alg, key)
+		}
+		return SignHMAC(HMACSHA256, k, input), nil
+	case RS256:
+		k, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, keyTypeError(alg, key)
+		}
+		d := sha256.Sum256(input)
+		return rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, d[:])
+	case ES256:
+		k, ok := key.(*ecdsa.PrivateKey)
+		if !ok || k.Curve != elliptic.P256() {
+			return nil, keyTypeError(alg, key)
+		}
+		d := sha256.Sum256(input)
+		r, s, err := ecdsa.Sign(rand.Reader, k, d[:])
+		if err != nil {
+			return nil, err
+		}
+		// JWS uses fixed length R || S instead of ASN.1.
+		sig := make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+		return sig, nil
+	case EdDSA:
+		k, ok := key.(ed25519.PrivateKey)
+		if !ok || len(k) != ed25519.PrivateKeySize {
+			return nil, keyTypeError(alg, key)
+		}
+		return ed25519.Sign(k, input), nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownAlgorithm, alg)
+}
+
+// jwsVerify verifies given signature of given input with given algorithm and key.
+func jwsVerify(alg JWTAlgorithm, key interface{}, input, sig []byte) error {
+	ok := false
+	switch alg {
+	case HS256:
+		k, isKey := key.([]byte)
+		if !isKey || len(k) == 0 {
+			return keyTypeError(alg, key)
+		}
+		ok = VerifyHMAC(HMACSHA256, k, input, sig)
+	case RS256:
+		k, isKey := key.(*rsa.PublicKey)
+		if !isKey {
+			return keyTypeError(alg, key)
+		}
+		d := sha256.Sum256(input)
+		ok = rsa.VerifyPKCS1v15(k, crypto.SHA256, d[:], sig) == nil
+	case ES256:
+		k, isKey := key.(*ecdsa.PublicKey)
+		if !isKey || k.Curve != elliptic.P256() {
+			return keyTypeError(alg, key)
+		}
+		if len(sig) == 64 {
+			d := sha256.Sum256(input)
+			ok = ecdsa.Verify(k, d[:], new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:]))
+		}
+	case EdDSA:
+		k, isKey := key.(ed25519.PublicKey)
+		if !isKey || len(k) != ed25519.PublicKeySize {
+			return keyTypeError(alg, key)
+		}
+		ok = ed25519.Verify(k, input, sig)
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownAlgorithm, alg)
+	}
+	if !ok {
+		return ErrInvalidSignature
+	}
+	return nil
+}
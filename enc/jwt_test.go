@@ -0,0 +1,169 @@
+package enc_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marrbor/goutil/enc"
+	"github.com/stretchr/testify/assert"
+)
+
+type userClaims struct {
+	enc.Claims
+	Name  string `json:"name"`
+	Admin bool   `json:"admin"`
+}
+
+var jwtNow = time.Unix(1700000000, 0)
+
+func jwtKeys(t *testing.T) map[enc.JWTAlgorithm][2]interface{} {
+	rk, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	ek, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	return map[enc.JWTAlgorithm][2]interface{}{
+		enc.HS256: {[]byte("secret"), []byte("secret")},
+		enc.RS256: {rk, &rk.PublicKey},
+		enc.ES256: {ek, &ek.PublicKey},
+		enc.EdDSA: {priv, pub},
+	}
+}
+
+func TestJWT(t *testing.T) {
+	for alg, keys := range jwtKeys(t) {
+		s := &enc.JWTSigner{Algorithm: alg, Key: keys[0], KeyID: "k1"}
+		token, err := s.Sign(userClaims{
+			Claims: enc.Claims{
+				Issuer:    "goutil",
+				Subject:   "user1",
+				Audience:  enc.Audience{"api"},
+				ExpiresAt: enc.NewNumericDate(jwtNow.Add(time.Hour)),
+				IssuedAt:  enc.NewNumericDate(jwtNow),
+			},
+			Name:  "alice",
+			Admin: true,
+		})
+		assert.NoError(t, err, alg)
+
+		v := &enc.JWTVerifier{Algorithm: alg, Key: keys[1], Issuer: "goutil", Audience: "api", RequireExp: true,
+			Now: func() time.Time { return jwtNow }}
+		var c userClaims
+		assert.NoError(t, v.Verify(token, &c), alg)
+		assert.EqualValues(t, "alice", c.Name)
+		assert.True(t, c.Admin)
+		assert.EqualValues(t, "user1", c.Subject)
+		assert.EqualValues(t, enc.Audience{"api"}, c.Audience)
+
+		// tampered payload.
+		parts := strings.Split(token, ".")
+		forged := parts[0] + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"name":"mallory","admin":true}`)) + "." + parts[2]
+		assert.True(t, errors.Is(v.Verify(forged, nil), enc.ErrInvalidSignature), alg)
+
+		// kid is passed to KeyFunc.
+		kv := *v
+		kv.Key = nil
+		kv.KeyFunc = func(kid string) (interface{}, error) {
+			assert.EqualValues(t, "k1", kid)
+			return keys[1], nil
+		}
+		assert.NoError(t, kv.Verify(token, nil))
+	}
+}
+
+func TestJWT_Claims(t *testing.T) {
+	s := &enc.JWTSigner{Algorithm: enc.HS256, Key: []byte("secret")}
+	now := jwtNow
+	v := &enc.JWTVerifier{Algorithm: enc.HS256, Key: []byte("secret"), Leeway: time.Minute,
+		Now: func() time.Time { return now }}
+
+	sign := func(c enc.Claims) string {
+		token, err := s.Sign(c)
+		assert.NoError(t, err)
+		return token
+	}
+
+	// exp with leeway.
+	token := sign(enc.Claims{ExpiresAt: enc.NewNumericDate(jwtNow)})
+	assert.NoError(t, v.Verify(token, nil))
+	now = jwtNow.Add(time.Minute)
+	assert.True(t, errors.Is(v.Verify(token, nil), enc.ErrExpired))
+	now = jwtNow
+
+	// nbf and iat.
+	assert.NoError(t, v.Verify(sign(enc.Claims{NotBefore: enc.NewNumericDate(jwtNow.Add(time.Minute - time.Second))}), nil))
+	assert.True(t, errors.Is(v.Verify(sign(enc.Claims{NotBefore: enc.NewNumericDate(jwtNow.Add(2 * time.Minute))}), nil), enc.ErrTokenNotYetValid))
+	assert.True(t, errors.Is(v.Verify(sign(enc.Claims{IssuedAt: enc.NewNumericDate(jwtNow.Add(2 * time.Minute))}), nil), enc.ErrInvalidClaim))
+
+	// iss and aud.
+	v.Issuer = "goutil"
+	v.Audience = "api"
+	assert.NoError(t, v.Verify(sign(enc.Claims{Issuer: "goutil", Audience: enc.Audience{"web", "api"}}), nil))
+	assert.True(t, errors.Is(v.Verify(sign(enc.Claims{Issuer: "other", Audience: enc.Audience{"api"}}), nil), enc.ErrInvalidClaim))
+	assert.True(t, errors.Is(v.Verify(sign(enc.Claims{Issuer: "goutil", Audience: enc.Audience{"web"}}), nil), enc.ErrInvalidClaim))
+
+	// exp required.
+	v.RequireExp = true
+	assert.True(t, errors.Is(v.Verify(sign(enc.Claims{Issuer: "goutil", Audience: enc.Audience{"api"}}), nil), enc.ErrInvalidClaim))
+}
+
+func TestJWT_RFC7515(t *testing.T) {
+	// RFC 7515 Appendix A.1
+	key, err := base64.RawURLEncoding.DecodeString("AyM1SysPpbyDfgZld3umj1qzKObwVMkoqQ-EstJQLr_T-1qS0gZH75aKtMN3Yj0iPS4hcgUuTwjAzZr1Z9CAow")
+	assert.NoError(t, err)
+	token := "eyJ0eXAiOiJKV1QiLA0KICJhbGciOiJIUzI1NiJ9." +
+		"eyJpc3MiOiJqb2UiLA0KICJleHAiOjEzMDA4MTkzODAsDQogImh0dHA6Ly9leGFtcGxlLmNvbS9pc19yb290Ijp0cnVlfQ." +
+		"dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	v := &enc.JWTVerifier{Algorithm: enc.HS256, Key: key, Now: func() time.Time { return time.Unix(1300819000, 0) }}
+	var c enc.Claims
+	assert.NoError(t, v.Verify(token, &c))
+	assert.EqualValues(t, "joe", c.Issuer)
+
+	v.Now = nil
+	assert.True(t, errors.Is(v.Verify(token, nil), enc.ErrExpired))
+}
+
+func TestJWT_Attacks(t *testing.T) {
+	keys := jwtKeys(t)
+	enc64 := base64.RawURLEncoding.EncodeToString
+	payload := enc64([]byte(`{"sub":"admin"}`))
+
+	// alg none.
+	none := enc64([]byte(`{"alg":"none","typ":"JWT"}`)) + "." + payload + "."
+	v := &enc.JWTVerifier{Algorithm: enc.HS256, Key: []byte("secret")}
+	assert.True(t, errors.Is(v.Verify(none, nil), enc.ErrAlgorithmMismatch))
+	v = &enc.JWTVerifier{Algorithm: "none"}
+	assert.True(t, errors.Is(v.Verify(none, nil), enc.ErrUnknownAlgorithm))
+
+	// HS256 token signed with RSA public key bytes is rejected by RS256 verifier.
+	rpub := keys[enc.RS256][1].(*rsa.PublicKey)
+	hs := &enc.JWTSigner{Algorithm: enc.HS256, Key: rpub.N.Bytes()}
+	token, err := hs.Sign(enc.Claims{Subject: "admin"})
+	assert.NoError(t, err)
+	v = &enc.JWTVerifier{Algorithm: enc.RS256, Key: rpub}
+	assert.True(t, errors.Is(v.Verify(token, nil), enc.ErrAlgorithmMismatch))
+
+	// key that does not match algorithm.
+	v = &enc.JWTVerifier{Algorithm: enc.HS256, Key: rpub}
+	assert.True(t, errors.Is(v.Verify(token, nil), enc.ErrInvalidKey))
+	_, err = (&enc.JWTSigner{Algorithm: enc.ES256, Key: []byte("x")}).Sign(nil)
+	assert.True(t, errors.Is(err, enc.ErrInvalidKey))
+
+	// crit header.
+	crit := enc64([]byte(`{"alg":"HS256","crit":["exp"]}`)) + "." + payload + ".AA"
+	v = &enc.JWTVerifier{Algorithm: enc.HS256, Key: []byte("secret")}
+	assert.True(t, errors.Is(v.Verify(crit, nil), enc.ErrInvalidToken))
+
+	for _, tk := range []string{"", "a.b", "a.b.c.d", "!.b.c", enc64([]byte("{")) + ".b.c"} {
+		assert.True(t, errors.Is(v.Verify(tk, nil), enc.ErrInvalidToken), tk)
+	}
+}
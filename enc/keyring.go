@@ -0,0 +1,310 @@
+package enc
+
+import (
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrKeyNotFound  = errors.New("key not found")
+	ErrDuplicateKey = errors.New("duplicate key id")
+	ErrNoPrimaryKey = errors.New("no primary key")
+	ErrInvalidMAC   = errors.New("invalid mac")
+)
+
+// macKeyInfo is HKDF info to derive MAC key from the secret of Key.
+const macKeyInfo = "goutil/enc keyring mac"
+
+// KeyRing holds versioned keys. The primary key is used for new encryption and MAC, and the key used for
+// decryption and verification is selected by the key id stored in the envelope and the MAC.
+// KeyRing is safe for concurrent use.
+type KeyRing struct {
+	mu      sync.RWMutex
+	keys    map[string]*Key
+	primary string
+}
+
+// NewKeyRing returns a new key ring holding given keys. The first key becomes primary.
+func NewKeyRing(keys ...*Key) (*KeyRing, error) {
+	r := &KeyRing{keys: make(map[string]*Key)}
+	for _, k := range keys {
+		if err := r.Add(k); err != nil {
+			return nil, err
+		}
+	}
+	if len(keys) > 0 {
+		r.primary = keys[0].ID
+	}
+	return r, nil
+}
+
+// Add adds given key. The key becomes primary when the ring has no primary key.
+func (r *KeyRing) Add(k *Key) error {
+	if _, err := k.aead(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.keys[k.ID]; ok {
+		return fmt.Errorf("%w: %q", ErrDuplicateKey, k.ID)
+	}
+	if _, ok := r.keys[r.primary]; !ok {
+		r.primary = k.ID
+	}
+	r.keys[k.ID] = k
+	return nil
+}
+
+// Remove removes the key that has given id. Primary key can not be removed.
+func (r *KeyRing) Remove(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.keys[id]; !ok {
+		return fmt.Errorf("%w: %q", ErrKeyNotFound, id)
+	}
+	if id == r.primary {
+		return fmt.Errorf("primary key %q can not be removed", id)
+	}
+	delete(r.keys, id)
+	return nil
+}
+
+// SetPrimary makes the key that has given id primary.
+func (r *KeyRing) SetPrimary(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.keys[id]; !ok {
+		return fmt.Errorf("%w: %q", ErrKeyNotFound, id)
+	}
+	r.primary = id
+	return nil
+}
+
+// Rotate adds a new random key that has given id and makes it primary. Old keys are kept for decryption.
+func (r *KeyRing) Rotate(id string, alg AEADAlgorithm) (*Key, error) {
+	k, err := NewKey(id, alg)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.Add(k); err != nil {
+		return nil, err
+	}
+	return k, r.SetPrimary(id)
+}
+
+// Primary returns the primary key.
+func (r *KeyRing) Primary() (*Key, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	k, ok := r.keys[r.primary]
+	if !ok {
+		return nil, ErrNoPrimaryKey
+	}
+	return k, nil
+}
+
+// Key returns the key that has given id.
+func (r *KeyRing) Key(id string) (*Key, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	k, ok := r.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrKeyNotFound, id)
+	}
+	return k, nil
+}
+
+// IDs returns sorted ids of held keys.
+func (r *KeyRing) IDs() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ids := make([]string, 0, len(r.keys))
+	for id := range r.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Seal encrypts given plaintext with the primary key. See Seal.
+func (r *KeyRing) Seal(plaintext, ad []byte) ([]byte, error) {
+	k, err := r.Primary()
+	if err != nil {
+		return nil, err
+	}
+	return Seal(k, plaintext, ad)
+}
+
+// Open decrypts given envelope with the key that has the key id stored in the envelope. See Open.
+func (r *KeyRing) Open(envelope, ad []byte) ([]byte, error) {
+	e, err := ParseEnvelope(envelope)
+	if err != nil {
+		return nil, err
+	}
+	k, err := r.Key(e.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	return e.open(k, ad)
+}
+
+// macWith returns HMAC-SHA256 of given message with the key derived from the secret of given key.
+// Derived key is used not to use the same key for encryption and MAC.
+func macWith(k *Key, msg []byte) ([]byte, error) {
+	mk, err := hkdf.Key(sha256.New, k.Secret, nil, macKeyInfo, sha256.Size)
+	if err != nil {
+		return nil, err
+	}
+	return SignHMAC(HMACSHA256, mk, msg), nil
+}
+
+// MAC returns HMAC-SHA256 of given message with the primary key and its key id.
+func (r *KeyRing) MAC(msg []byte) (string, []byte, error) {
+	k, err := r.Primary()
+	if err != nil {
+		return "", nil, err
+	}
+	mac, err := macWith(k, msg)
+	if err != nil {
+		return "", nil, err
+	}
+	return k.ID, mac, nil
+}
+
+// VerifyMAC verifies given MAC of given message with the key that has given key id.
+func (r *KeyRing) VerifyMAC(keyID string, msg, mac []byte) error {
+	k, err := r.Key(keyID)
+	if err != nil {
+		return err
+	}
+	want, err := macWith(k, msg)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal(want, mac) {
+		return ErrInvalidMAC
+	}
+	return nil
+}
+
+type (
+	// keyRingJSON is JSON form of KeyRing.
+	keyRingJSON struct {
+		Primary string    `json:"primary"`
+		Keys    []keyJSON `json:"keys"`
+	}
+
+	// keyJSON is JSON form of Key. Secret is encoded in base64url.
+	keyJSON struct {
+		ID        string        `json:"id"`
+		Algorithm AEADAlgorithm `json:"algorithm"`
+		Secret    string        `json:"secret"`
+	}
+)
+
+// MarshalJSON returns JSON form of the key ring that can be parsed by ParseKeyRing when the ring has a key.
+func (r *KeyRing) MarshalJSON() ([]byte, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	j := keyRingJSON{Primary: r.primary, Keys: []keyJSON{}}
+	for _, k := range r.keys {
+		j.Keys = append(j.Keys, keyJSON{ID: k.ID, Algorithm: k.Algorithm, Secret: b64url.EncodeToString(k.Secret)})
+	}
+	sort.Slice(j.Keys, func(a, b int) bool { return j.Keys[a].ID < j.Keys[b].ID })
+	return json.Marshal(j)
+}
+
+// ParseKeyRing parses JSON form of key ring like:
+//
+//	{"primary": "2024", "keys": [{"id": "2024", "algorithm": "AES-256-GCM", "secret": "<base64url>"}]}
+//
+// "primary" can be omitted only when one key is given, otherwise ErrNoPrimaryKey is returned.
+func ParseKeyRing(b []byte) (*KeyRing, error) {
+	var j keyRingJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return nil, err
+	}
+	r, _ := NewKeyRing()
+	for _, kj := range j.Keys {
+		secret, err := b64url.DecodeString(kj.Secret)
+		if err != nil {
+			return nil, fmt.Errorf("%w: key %q: %v", ErrInvalidKey, kj.ID, err)
+		}
+		if err := r.Add(&Key{ID: kj.ID, Algorithm: kj.Algorithm, Secret: secret}); err != nil {
+			return nil, err
+		}
+	}
+	if err := r.setLoadedPrimary(j.Primary); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// LoadKeyRing reads JSON form of key ring from given file. See ParseKeyRing.
+func LoadKeyRing(path string) (*KeyRing, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseKeyRing(b)
+}
+
+// LoadKeyRingEnv reads key ring from environment variables that have given prefix:
+//
+//	<prefix>_KEY_<id>=<algorithm>:<base64url secret>
+//	<prefix>_PRIMARY=<id>
+//
+// <prefix>_PRIMARY can be omitted only when one key is given, otherwise ErrNoPrimaryKey is returned like
+// ParseKeyRing.
+func LoadKeyRingEnv(prefix string) (*KeyRing, error) {
+	r, _ := NewKeyRing()
+	keyPrefix := prefix + "_KEY_"
+	for _, kv := range os.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(name, keyPrefix) {
+			continue
+		}
+		id := strings.TrimPrefix(name, keyPrefix)
+		algName, secretStr, ok := strings.Cut(value, ":")
+		if !ok {
+			return nil, fmt.Errorf("%w: %s has no algorithm", ErrInvalidKey, name)
+		}
+		alg, err := ParseAEADAlgorithm(algName)
+		if err != nil {
+			return nil, err
+		}
+		secret, err := b64url.DecodeString(secretStr)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidKey, name, err)
+		}
+		if err := r.Add(&Key{ID: id, Algorithm: alg, Secret: secret}); err != nil {
+			return nil, err
+		}
+	}
+	if err := r.setLoadedPrimary(os.Getenv(prefix + "_PRIMARY")); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// setLoadedPrimary sets the primary key of loaded key ring. Empty id is allowed only when the ring has one key, since
+// which key is primary can not be decided from the order of keys.
+func (r *KeyRing) setLoadedPrimary(id string) error {
+	if id != "" {
+		return r.SetPrimary(id)
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if len(r.keys) != 1 {
+		return ErrNoPrimaryKey
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package enc_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/marrbor/goutil/enc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyRing(t *testing.T) {
+	k1, err := enc.NewKey("v1", enc.AES256GCM)
+	assert.NoError(t, err)
+	r, err := enc.NewKeyRing(k1)
+	assert.NoError(t, err)
+
+	old, err := r.Seal([]byte("old"), nil)
+	assert.NoError(t, err)
+	oldID, oldMAC, err := r.MAC([]byte("old"))
+	assert.NoError(t, err)
+	assert.EqualValues(t, "v1", oldID)
+
+	// rotate key.
+	k2, err := r.Rotate("v2", enc.ChaCha20Poly1305)
+	assert.NoError(t, err)
+	p, err := r.Primary()
+	assert.NoError(t, err)
+	assert.Equal(t, k2, p)
+	assert.EqualValues(t, []string{"v1", "v2"}, r.IDs())
+
+	c, err := r.Seal([]byte("new"), nil)
+	assert.NoError(t, err)
+	e, err := enc.ParseEnvelope(c)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "v2", e.KeyID)
+
+	// both old and new ciphertexts can be decrypted.
+	m, err := r.Open(old, nil)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "old", string(m))
+	m, err = r.Open(c, nil)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "new", string(m))
+
+	// old MAC is verified with old key.
+	assert.NoError(t, r.VerifyMAC(oldID, []byte("old"), oldMAC))
+	assert.True(t, errors.Is(r.VerifyMAC(oldID, []byte("new"), oldMAC), enc.ErrInvalidMAC))
+	assert.True(t, errors.Is(r.VerifyMAC("v2", []byte("old"), oldMAC), enc.ErrInvalidMAC))
+
+	// retire old key.
+	assert.Error(t, r.Remove("v2"))
+	assert.NoError(t, r.Remove("v1"))
+	_, err = r.Open(old, nil)
+	assert.True(t, errors.Is(err, enc.ErrKeyNotFound))
+	assert.True(t, errors.Is(r.VerifyMAC(oldID, []byte("old"), oldMAC), enc.ErrKeyNotFound))
+	assert.True(t, errors.Is(r.Remove("v1"), enc.ErrKeyNotFound))
+	assert.True(t, errors.Is(r.SetPrimary("v1"), enc.ErrKeyNotFound))
+
+	assert.True(t, errors.Is(r.Add(k2), enc.ErrDuplicateKey))
+}
+
+func TestKeyRing_Empty(t *testing.T) {
+	r, err := enc.NewKeyRing()
+	assert.NoError(t, err)
+	_, err = r.Seal([]byte("x"), nil)
+	assert.True(t, errors.Is(err, enc.ErrNoPrimaryKey))
+	_, _, err = r.MAC([]byte("x"))
+	assert.True(t, errors.Is(err, enc.ErrNoPrimaryKey))
+}
+
+func TestLoadKeyRing(t *testing.T) {
+	r, err := enc.NewKeyRing()
+	assert.NoError(t, err)
+	_, err = r.Rotate("a", enc.AES256GCM)
+	assert.NoError(t, err)
+	_, err = r.Rotate("b", enc.ChaCha20Poly1305)
+	assert.NoError(t, err)
+	assert.NoError(t, r.SetPrimary("a"))
+	c, err := r.Seal([]byte("data"), nil)
+	assert.NoError(t, err)
+
+	b, err := r.MarshalJSON()
+	assert.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "keys.json")
+	assert.NoError(t, os.WriteFile(path, b, 0600))
+
+	loaded, err := enc.LoadKeyRing(path)
+	assert.NoError(t, err)
+	assert.EqualValues(t, []string{"a", "b"}, loaded.IDs())
+	p, err := loaded.Primary()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "a", p.ID)
+	m, err := loaded.Open(c, nil)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "data", string(m))
+
+	_, err = enc.ParseKeyRing([]byte(`{"keys":[{"id":"x","algorithm":"DES","secret":""}]}`))
+	assert.True(t, errors.Is(err, enc.ErrUnknownAlgorithm))
+	_, err = enc.ParseKeyRing([]byte(`{"keys":[{"id":"x","algorithm":"AES-256-GCM","secret":"AAAA"}]}`))
+	assert.True(t, errors.Is(err, enc.ErrInvalidKey))
+	_, err = enc.ParseKeyRing([]byte(`{"primary":"y","keys":[]}`))
+	assert.True(t, errors.Is(err, enc.ErrKeyNotFound))
+
+	// primary is required unless only one key is given, same as LoadKeyRingEnv.
+	secret := base64.RawURLEncoding.EncodeToString(make([]byte, enc.KeySize))
+	keyA := `{"id":"a","algorithm":"AES-256-GCM","secret":"` + secret + `"}`
+	keyB := `{"id":"b","algorithm":"AES-256-GCM","secret":"` + secret + `"}`
+	one, err := enc.ParseKeyRing([]byte(`{"keys":[` + keyA + `]}`))
+	assert.NoError(t, err)
+	p, err = one.Primary()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "a", p.ID)
+	_, err = enc.ParseKeyRing([]byte(`{"keys":[` + keyA + `,` + keyB + `]}`))
+	assert.True(t, errors.Is(err, enc.ErrNoPrimaryKey))
+	_, err = enc.ParseKeyRing([]byte(`{"keys":[]}`))
+	assert.True(t, errors.Is(err, enc.ErrNoPrimaryKey))
+	_, err = enc.LoadKeyRing(filepath.Join(t.TempDir(), "none.json"))
+	assert.Error(t, err)
+}
+
+func TestLoadKeyRingEnv(t *testing.T) {
+	secret := base64.RawURLEncoding.EncodeToString(make([]byte, enc.KeySize))
+	t.Setenv("GOUTIL_TEST_KEY_k1", "AES-256-GCM:"+secret)
+
+	r, err := enc.LoadKeyRingEnv("GOUTIL_TEST")
+	assert.NoError(t, err)
+	assert.EqualValues(t, []string{"k1"}, r.IDs())
+
+	t.Setenv("GOUTIL_TEST_KEY_k2", "ChaCha20-Poly1305:"+secret)
+	_, err = enc.LoadKeyRingEnv("GOUTIL_TEST")
+	assert.True(t, errors.Is(err, enc.ErrNoPrimaryKey))
+
+	t.Setenv("GOUTIL_TEST_PRIMARY", "k2")
+	r, err = enc.LoadKeyRingEnv("GOUTIL_TEST")
+	assert.NoError(t, err)
+	p, err := r.Primary()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "k2", p.ID)
+	assert.EqualValues(t, enc.ChaCha20Poly1305, p.Algorithm)
+
+	// no key.
+	_, err = enc.LoadKeyRingEnv("GOUTIL_NONE")
+	assert.True(t, errors.Is(err, enc.ErrNoPrimaryKey))
+
+	t.Setenv("GOUTIL_TEST_KEY_k3", secret)
+	_, err = enc.LoadKeyRingEnv("GOUTIL_TEST")
+	assert.True(t, errors.Is(err, enc.ErrInvalidKey))
+}
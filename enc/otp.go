@@ -0,0 +1,307 @@
+package enc
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OTPAlgorithm is a hash function of HOTP and TOTP. The zero value is SHA1 that every authenticator app supports.
+type OTPAlgorithm int
+
+const (
+	OTPSHA1 OTPAlgorithm = iota
+	OTPSHA256
+	OTPSHA512
+)
+
+// Default parameters of one-time password.
+const (
+	DefaultOTPDigits    = 6
+	DefaultOTPPeriod    = 30 * time.Second
+	DefaultOTPSecretLen = 20
+)
+
+// ErrInvalidOTP is returned when given one-time password does not match.
+var ErrInvalidOTP = errors.New("invalid one-time password")
+
+// otpBase32 is the encoding of the secret in otpauth URI.
+var otpBase32 = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// String returns the name used in otpauth URI.
+func (a OTPAlgorithm) String() string {
+	switch a {
+	case OTPSHA1:
+		return "SHA1"
+	case OTPSHA256:
+		return "SHA256"
+	case OTPSHA512:
+		return "SHA512"
+	}
+	return "OTPAlgorithm(" + strconv.Itoa(int(a)) + ")"
+}
+
+// newHash returns hash constructor of the algorithm.
+func (a OTPAlgorithm) newHash() (func() hash.Hash, error) {
+	switch a {
+	case OTPSHA1:
+		return sha1.New, nil
+	case OTPSHA256:
+		return sha256.New, nil
+	case OTPSHA512:
+		return sha512.New, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownAlgorithm, a)
+}
+
+// OTP generates and verifies HOTP (RFC 4226) and TOTP (RFC 6238) one-time passwords.
+type OTP struct {
+	Secret    []byte
+	Algorithm OTPAlgorithm
+	Digits    int              // number of digits from 6 to 8, DefaultOTPDigits is used when 0.
+	Period    time.Duration    // time step of TOTP, DefaultOTPPeriod is used when 0.
+	Skew      int              // number of time steps accepted before and after current one on TOTP verification.
+	Now       func() time.Time // returns current time, time.Now is used when nil.
+}
+
+// NewOTP returns OTP that has new random secret and default parameters.
+func NewOTP() (*OTP, error) {
+	secret := make([]byte, DefaultOTPSecretLen)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	return &OTP{Secret: secret}, nil
+}
+
+// ParseOTPSecret returns the secret of given base32 string shown to the user. Spaces and padding are ignored.
+func ParseOTPSecret(s string) ([]byte, error) {
+	s = strings.ToUpper(strings.TrimRight(strings.ReplaceAll(s, " ", ""), "="))
+	return otpBase32.DecodeString(s)
+}
+
+// SecretString returns base32 string of the secret for manual entry.
+func (o *OTP) SecretString() string {
+	return otpBase32.EncodeToString(o.Secret)
+}
+
+// digits returns the number of digits.
+func (o *OTP) digits() (int, error) {
+	if o.Digits == 0 {
+		return DefaultOTPDigits, nil
+	}
+	if o.Digits < 6 || 8 < o.Digits {
+		return 0, fmt.Errorf("otp digits must be 6 to 8, got %d", o.Digits)
+	}
+	return o.Digits, nil
+}
+
+// period returns the time step.
+func (o *OTP) period() time.Duration {
+	if o.Period <= 0 {
+		return DefaultOTPPeriod
+	}
+	return o.Period
+}
+
+// HOTP returns HOTP of given counter.
+func (o *OTP) HOTP(counter uint64) (string, error) {
+	digits, err := o.digits()
+	if err != nil {
+		return "", err
+	}
+	h, err := o.Algorithm.newHash()
+	if err != nil {
+		return "", err
+	}
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+	m := hmac.New(h, o.Secret)
+	m.Write(msg[:])
+	sum := m.Sum(nil)
+
+	// dynamic truncation.
+	off := sum[len(sum)-1] & 0x0f
+	code := binary.BigEndian.Uint32(sum[off:]) & 0x7fffffff
+	mod := uint32(1)
+	for i := 0; i < digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", digits, code%mod), nil
+}
+
+// VerifyHOTP verifies given code with counters from given one to counter+window and returns the counter to be
+// stored for next verification, that is the matched counter + 1.
+func (o *OTP) VerifyHOTP(code string, counter uint64, window int) (uint64, error) {
+	for i := 0; i <= window; i++ {
+		ok, err := o.match(code, counter+uint64(i))
+		if err != nil {
+			return counter, err
+		}
+		if ok {
+			return counter + uint64(i) + 1, nil
+		}
+	}
+	return counter, ErrInvalidOTP
+}
+
+// step returns TOTP time step of given time.
+func (o *OTP) step(t time.Time) uint64 {
+	return uint64(t.Unix() / max(int64(o.period()/time.Second), 1))
+}
+
+// now returns current time.
+func (o *OTP) now() time.Time {
+	if o.Now != nil {
+		return o.Now()
+	}
+	return time.Now()
+}
+
+// TOTP returns current TOTP.
+func (o *OTP) TOTP() (string, error) {
+	return o.TOTPAt(o.now())
+}
+
+// TOTPAt returns TOTP at given time.
+func (o *OTP) TOTPAt(t time.Time) (string, error) {
+	return o.HOTP(o.step(t))
+}
+
+// VerifyTOTP verifies given code allowing Skew steps of clock drift and returns the matched time step.
+// A code is valid for its whole step, so store the returned step and reject codes whose step is not greater
+// than the stored one to prevent replay.
+func (o *OTP) VerifyTOTP(code string) (uint64, error) {
+	now := o.step(o.now())
+	for i := -o.Skew; i <= o.Skew; i++ {
+		if i < 0 && now < uint64(-i) {
+			continue
+		}
+		s := now + uint64(i)
+		ok, err := o.match(code, s)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			return s, nil
+		}
+	}
+	return 0, ErrInvalidOTP
+}
+
+// match returns whether given code is HOTP of given counter or not. The comparison takes constant time.
+func (o *OTP) match(code string, counter uint64) (bool, error) {
+	want, err := o.HOTP(counter)
+	if err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare([]byte(want), []byte(code)) == 1, nil
+}
+
+// TOTPURI returns otpauth URI of TOTP to be shown as QR code for authenticator apps.
+func (o *OTP) TOTPURI(issuer, account string) string {
+	q := o.uriParams(issuer)
+	if o.Period != 0 && o.period() != DefaultOTPPeriod {
+		q.Set("period", strconv.Itoa(int(o.period()/time.Second)))
+	}
+	return o.uri("totp", issuer, account, q)
+}
+
+// HOTPURI returns otpauth URI of HOTP that starts with given counter.
+func (o *OTP) HOTPURI(issuer, account string, counter uint64) string {
+	q := o.uriParams(issuer)
+	q.Set("counter", strconv.FormatUint(counter, 10))
+	return o.uri("hotp", issuer, account, q)
+}
+
+// uriParams returns common query parameters of otpauth URI.
+func (o *OTP) uriParams(issuer string) url.Values {
+	q := url.Values{}
+	q.Set("secret", o.SecretString())
+	if issuer != "" {
+		q.Set("issuer", issuer)
+	}
+	q.Set("algorithm", o.Algorithm.String())
+	digits, err := o.digits()
+	if err != nil {
+		digits = o.Digits
+	}
+	q.Set("digits", strconv.Itoa(digits))
+	return q
+}
+
+// uri returns otpauth URI of given type, label and query.
+func (o *OTP) uri(typ, issuer, account string, q url.Values) string {
+	label := account
+	if issuer != "" {
+		label = issuer + ":" + account
+	}
+	u := url.URL{Scheme: "otpauth", Host: typ, Path: "/" + label, RawQuery: q.Encode()}
+	return u.String()
+}
+
+// RecoveryCodeLen is the number of characters of a recovery code without hyphen.
+const RecoveryCodeLen = 10
+
+// NewRecoveryCodes returns n random recovery codes like "7D2KQ-M9XTA" in Crockford Base32, which has no
+// confusing characters. Store their hashes generated by HashPassword(NormalizeRecoveryCode(code)) and show the
+// codes to the user once.
+func NewRecoveryCodes(n int) ([]string, error) {
+	codes := make([]string, n)
+	buf := make([]byte, RecoveryCodeLen)
+	for i := range codes {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+		for j, b := range buf {
+			buf[j] = crockfordAlphabet[b&0x1f]
+		}
+		codes[i] = string(buf[:RecoveryCodeLen/2]) + "-" + string(buf[RecoveryCodeLen/2:])
+	}
+	return codes, nil
+}
+
+// NormalizeRecoveryCode returns canonical form of given recovery code typed by the user. It is case insensitive,
+// ignores hyphens and spaces and reads 'I', 'L' as '1' and 'O' as '0'.
+func NormalizeRecoveryCode(code string) string {
+	var sb strings.Builder
+	for i := 0; i < len(code); i++ {
+		if code[i] == ' ' {
+			continue
+		}
+		if c, ok := crockfordNormalize(code[i]); ok {
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+// UseRecoveryCode returns the index of the hash that matches given recovery code, or ErrInvalidOTP.
+// Remove the matched hash from the storage so that the code can not be used again.
+func UseRecoveryCode(code string, hashes []string) (int, error) {
+	code = NormalizeRecoveryCode(code)
+	if len(code) != RecoveryCodeLen {
+		return -1, ErrInvalidOTP
+	}
+	for i, h := range hashes {
+		ok, err := VerifyPassword(code, h)
+		if err != nil {
+			return -1, err
+		}
+		if ok {
+			return i, nil
+		}
+	}
+	return -1, ErrInvalidOTP
+}
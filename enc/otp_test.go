@@ -0,0 +1,160 @@
+package enc_test
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marrbor/goutil/enc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOTP_HOTP(t *testing.T) {
+	// RFC 4226 appendix D.
+	o := &enc.OTP{Secret: []byte("12345678901234567890")}
+	want := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+	for i, w := range want {
+		code, err := o.HOTP(uint64(i))
+		assert.NoError(t, err)
+		assert.EqualValues(t, w, code, i)
+	}
+
+	next, err := o.VerifyHOTP("969429", 1, 3)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 4, next)
+	next, err = o.VerifyHOTP("969429", 4, 3)
+	assert.True(t, errors.Is(err, enc.ErrInvalidOTP))
+	assert.EqualValues(t, 4, next)
+	_, err = o.VerifyHOTP("520489", 0, 3)
+	assert.True(t, errors.Is(err, enc.ErrInvalidOTP))
+
+	for _, d := range []int{5, 9} {
+		_, err = (&enc.OTP{Secret: o.Secret, Digits: d}).HOTP(0)
+		assert.Error(t, err)
+	}
+}
+
+func TestOTP_TOTP(t *testing.T) {
+	// RFC 6238 appendix B.
+	secrets := map[enc.OTPAlgorithm]string{
+		enc.OTPSHA1:   "12345678901234567890",
+		enc.OTPSHA256: "12345678901234567890123456789012",
+		enc.OTPSHA512: "1234567890123456789012345678901234567890123456789012345678901234",
+	}
+	tests := []struct {
+		unix int64
+		want map[enc.OTPAlgorithm]string
+	}{
+		{59, map[enc.OTPAlgorithm]string{enc.OTPSHA1: "94287082", enc.OTPSHA256: "46119246", enc.OTPSHA512: "90693936"}},
+		{1111111109, map[enc.OTPAlgorithm]string{enc.OTPSHA1: "07081804", enc.OTPSHA256: "68084774", enc.OTPSHA512: "25091201"}},
+		{1111111111, map[enc.OTPAlgorithm]string{enc.OTPSHA1: "14050471", enc.OTPSHA256: "67062674", enc.OTPSHA512: "99943326"}},
+		{1234567890, map[enc.OTPAlgorithm]string{enc.OTPSHA1: "89005924", enc.OTPSHA256: "91819424", enc.OTPSHA512: "93441116"}},
+		{2000000000, map[enc.OTPAlgorithm]string{enc.OTPSHA1: "69279037", enc.OTPSHA256: "90698825", enc.OTPSHA512: "38618901"}},
+		{20000000000, map[enc.OTPAlgorithm]string{enc.OTPSHA1: "65353130", enc.OTPSHA256: "77737706", enc.OTPSHA512: "47863826"}},
+	}
+	for _, tt := range tests {
+		for alg, w := range tt.want {
+			o := &enc.OTP{Secret: []byte(secrets[alg]), Algorithm: alg, Digits: 8}
+			code, err := o.TOTPAt(time.Unix(tt.unix, 0))
+			assert.NoError(t, err)
+			assert.EqualValues(t, w, code, "%s %d", alg, tt.unix)
+		}
+	}
+}
+
+func TestOTP_VerifyTOTP(t *testing.T) {
+	o, err := enc.NewOTP()
+	assert.NoError(t, err)
+	assert.EqualValues(t, enc.DefaultOTPSecretLen, len(o.Secret))
+	now := time.Unix(1700000000, 0)
+	o.Now = func() time.Time { return now }
+	o.Skew = 1
+
+	code, err := o.TOTP()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 6, len(code))
+	step, err := o.VerifyTOTP(code)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1700000000/30, step)
+
+	// drift within skew.
+	now = now.Add(30 * time.Second)
+	step2, err := o.VerifyTOTP(code)
+	assert.NoError(t, err)
+	assert.EqualValues(t, step, step2)
+
+	now = now.Add(30 * time.Second)
+	_, err = o.VerifyTOTP(code)
+	assert.True(t, errors.Is(err, enc.ErrInvalidOTP))
+
+	_, err = o.VerifyTOTP("")
+	assert.True(t, errors.Is(err, enc.ErrInvalidOTP))
+
+	// longer step.
+	o.Period = time.Minute
+	code, err = o.TOTPAt(time.Unix(120, 0))
+	assert.NoError(t, err)
+	code2, err := o.TOTPAt(time.Unix(179, 0))
+	assert.NoError(t, err)
+	assert.EqualValues(t, code, code2)
+}
+
+func TestOTP_URI(t *testing.T) {
+	o := &enc.OTP{Secret: []byte("12345678901234567890"), Algorithm: enc.OTPSHA256, Digits: 8, Period: time.Minute}
+	assert.EqualValues(t, "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", o.SecretString())
+	secret, err := enc.ParseOTPSecret("gezd gnbv gy3t qojq gezd gnbv gy3t qojq")
+	assert.NoError(t, err)
+	assert.EqualValues(t, o.Secret, secret)
+
+	u, err := url.Parse(o.TOTPURI("Example Co", "alice@example.com"))
+	assert.NoError(t, err)
+	assert.EqualValues(t, "otpauth", u.Scheme)
+	assert.EqualValues(t, "totp", u.Host)
+	assert.EqualValues(t, "/Example Co:alice@example.com", u.Path)
+	q := u.Query()
+	assert.EqualValues(t, o.SecretString(), q.Get("secret"))
+	assert.EqualValues(t, "Example Co", q.Get("issuer"))
+	assert.EqualValues(t, "SHA256", q.Get("algorithm"))
+	assert.EqualValues(t, "8", q.Get("digits"))
+	assert.EqualValues(t, "60", q.Get("period"))
+
+	s := (&enc.OTP{Secret: o.Secret}).HOTPURI("", "bob", 5)
+	assert.True(t, strings.HasPrefix(s, "otpauth://hotp/bob?"), s)
+	u, err = url.Parse(s)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "5", u.Query().Get("counter"))
+	assert.EqualValues(t, "SHA1", u.Query().Get("algorithm"))
+	assert.EqualValues(t, "6", u.Query().Get("digits"))
+	assert.EqualValues(t, "", u.Query().Get("period"))
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := enc.NewRecoveryCodes(10)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 10, len(codes))
+	params := enc.PasswordParams{Algorithm: enc.PBKDF2SHA256, Iterations: 1, SaltLen: 16, KeyLen: 32}
+	hashes := make([]string, len(codes))
+	seen := map[string]bool{}
+	for i, c := range codes {
+		assert.EqualValues(t, enc.RecoveryCodeLen+1, len(c))
+		assert.EqualValues(t, '-', c[enc.RecoveryCodeLen/2])
+		assert.False(t, seen[c])
+		seen[c] = true
+		hashes[i], err = enc.HashPasswordWith(enc.NormalizeRecoveryCode(c), params)
+		assert.NoError(t, err)
+	}
+
+	i, err := enc.UseRecoveryCode(strings.ToLower(codes[3]), hashes)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, i)
+
+	hashes = append(hashes[:3], hashes[4:]...)
+	_, err = enc.UseRecoveryCode(codes[3], hashes)
+	assert.True(t, errors.Is(err, enc.ErrInvalidOTP))
+	_, err = enc.UseRecoveryCode("short", hashes)
+	assert.True(t, errors.Is(err, enc.ErrInvalidOTP))
+
+	assert.EqualValues(t, "0123411111", enc.NormalizeRecoveryCode("o1234-ilIL 1"))
+}
@@ -0,0 +1,233 @@
+package enc
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// PasswordAlgorithm is a key derivation function used for password hashing. Its value is the PHC identifier.
+type PasswordAlgorithm string
+
+const (
+	PBKDF2SHA256 PasswordAlgorithm = "pbkdf2-sha256"
+	PBKDF2SHA512 PasswordAlgorithm = "pbkdf2-sha512"
+	Scrypt       PasswordAlgorithm = "scrypt"
+	Argon2id     PasswordAlgorithm = "argon2id"
+
+	// legacySHA256 is the unsalted SHA-256 digest generated by Encrypt256Password.
+	legacySHA256 PasswordAlgorithm = "sha256"
+)
+
+var (
+	ErrInvalidPasswordHash = errors.New("invalid password hash")
+	ErrUnknownAlgorithm    = errors.New("unknown algorithm")
+)
+
+// PasswordParams holds algorithm and cost of password hashing.
+type PasswordParams struct {
+	Algorithm   PasswordAlgorithm
+	Iterations  int    // iteration count of PBKDF2, time cost of Argon2id.
+	Memory      uint32 // memory cost of Argon2id in KiB.
+	Parallelism int    // parallelism of scrypt (p) and Argon2id.
+	LogN        int    // log2 of CPU/memory cost of scrypt (N).
+	BlockSize   int    // block size of scrypt (r).
+	SaltLen     int    // salt length in bytes.
+	KeyLen      int    // derived key length in bytes.
+}
+
+// Recommended parameters for each algorithm. refer: https://cheatsheetseries.owasp.org/cheatsheets/Password_Storage_Cheat_Sheet.html
+var (
+	PBKDF2SHA256Params = PasswordParams{Algorithm: PBKDF2SHA256, Iterations: 600000, SaltLen: 16, KeyLen: 32}
+	PBKDF2SHA512Params = PasswordParams{Algorithm: PBKDF2SHA512, Iterations: 210000, SaltLen: 16, KeyLen: 64}
+	ScryptParams       = PasswordParams{Algorithm: Scrypt, LogN: 17, BlockSize: 8, Parallelism: 1, SaltLen: 16, KeyLen: 32}
+	Argon2idParams     = PasswordParams{Algorithm: Argon2id, Iterations: 2, Memory: 19456, Parallelism: 1, SaltLen: 16, KeyLen: 32}
+
+	// DefaultPasswordParams is used by HashPassword.
+	DefaultPasswordParams = Argon2idParams
+)
+
+// b64 is the base64 encoding used in PHC string format.
+var b64 = base64.RawStdEncoding
+
+// HashPassword returns PHC string format hash of given password with DefaultPasswordParams and random salt.
+func HashPassword(password string) (string, error) {
+	return HashPasswordWith(password, DefaultPasswordParams)
+}
+
+// HashPasswordWith returns PHC string format hash of given password with given parameters and random salt.
+func HashPasswordWith(password string, p PasswordParams) (string, error) {
+	if p.SaltLen <= 0 || p.KeyLen <= 0 {
+		return "", fmt.Errorf("%w: salt and key length must be positive", ErrInvalidPasswordHash)
+	}
+	salt := make([]byte, p.SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key, err := deriveKey(password, salt, p)
+	if err != nil {
+		return "", err
+	}
+	return formatPHC(p, salt, key), nil
+}
+
+// VerifyPassword returns whether given password matches given hash or not. The hash is PHC string format generated
+// by HashPassword, or hex SHA-256 digest generated by Encrypt256Password.
+func VerifyPassword(password, encoded string) (bool, error) {
+	p, salt, key, err := parsePHC(encoded)
+	if err != nil {
+		return false, err
+	}
+	var got []byte
+	if p.Algorithm == legacySHA256 {
+		d := sha256.Sum256([]byte(password))
+		got = d[:]
+	} else if got, err = deriveKey(password, salt, p); err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare(got, key) == 1, nil
+}
+
+// NeedsRehash returns whether given hash should be regenerated with given parameters or not.
+// It is intended to be called after successful VerifyPassword to migrate old hashes on next login.
+// Unparsable hash and legacy SHA-256 digest always need rehash.
+func NeedsRehash(encoded string, p PasswordParams) bool {
+	cur, _, _, err := parsePHC(encoded)
+	if err != nil {
+		return true
+	}
+	return cur != p
+}
+
+// Upper bounds of Argon2id costs accepted from stored hashes and parameters, not to exhaust memory or CPU.
+const (
+	maxArgon2Memory     = 4 << 20 // 4 GiB in KiB.
+	maxArgon2Iterations = 1024
+)
+
+// deriveKey derives key from given password and salt.
+func deriveKey(password string, salt []byte, p PasswordParams) ([]byte, error) {
+	switch p.Algorithm {
+	case PBKDF2SHA256, PBKDF2SHA512:
+		if p.Iterations <= 0 {
+			return nil, fmt.Errorf("%w: pbkdf2 iterations must be positive", ErrInvalidPasswordHash)
+		}
+		if p.Algorithm == PBKDF2SHA256 {
+			return pbkdf2.Key(sha256.New, password, salt, p.Iterations, p.KeyLen)
+		}
+		return pbkdf2.Key(sha512.New, password, salt, p.Iterations, p.KeyLen)
+	case Scrypt:
+		if p.LogN <= 0 || p.LogN >= 63 {
+			return nil, fmt.Errorf("%w: scrypt ln out of range", ErrInvalidPasswordHash)
+		}
+		if p.BlockSize <= 0 || p.Parallelism <= 0 {
+			return nil, fmt.Errorf("%w: scrypt r and p must be positive", ErrInvalidPasswordHash)
+		}
+		key, err := scrypt.Key([]byte(password), salt, 1<<p.LogN, p.BlockSize, p.Parallelism, p.KeyLen)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPasswordHash, err)
+		}
+		return key, nil
+	case Argon2id:
+		if p.Iterations <= 0 || p.Iterations > maxArgon2Iterations || p.Parallelism <= 0 || p.Parallelism > 255 ||
+			p.Memory < 8*uint32(p.Parallelism) || p.Memory > maxArgon2Memory {
+			return nil, fmt.Errorf("%w: argon2 parameter out of range", ErrInvalidPasswordHash)
+		}
+		return argon2.IDKey([]byte(password), salt, uint32(p.Iterations), p.Memory, uint8(p.Parallelism), uint32(p.KeyLen)), nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownAlgorithm, p.Algorithm)
+}
+
+// formatPHC returns PHC string format of given parameter, salt and key.
+func formatPHC(p PasswordParams, salt, key []byte) string {
+	var params string
+	switch p.Algorithm {
+	case PBKDF2SHA256, PBKDF2SHA512:
+		params = fmt.Sprintf("i=%d", p.Iterations)
+	case Scrypt:
+		params = fmt.Sprintf("ln=%d,r=%d,p=%d", p.LogN, p.BlockSize, p.Parallelism)
+	case Argon2id:
+		params = fmt.Sprintf("v=%d$m=%d,t=%d,p=%d", argon2.Version, p.Memory, p.Iterations, p.Parallelism)
+	}
+	return fmt.Sprintf("$%s$%s$%s$%s", p.Algorithm, params, b64.EncodeToString(salt), b64.EncodeToString(key))
+}
+
+// parsePHC parses given PHC string format hash. Hex SHA-256 digest is parsed as legacySHA256.
+func parsePHC(encoded string) (PasswordParams, []byte, []byte, error) {
+	var p PasswordParams
+	if !strings.HasPrefix(encoded, "$") {
+		key, err := hex.DecodeString(encoded)
+		if err != nil || len(key) != sha256.Size {
+			return p, nil, nil, ErrInvalidPasswordHash
+		}
+		p.Algorithm = legacySHA256
+		return p, nil, key, nil
+	}
+
+	fields := strings.Split(encoded[1:], "$")
+	p.Algorithm = PasswordAlgorithm(fields[0])
+	if p.Algorithm == Argon2id {
+		// argon2 has version field: $argon2id$v=19$m=...,t=...,p=...$salt$hash
+		if len(fields) != 5 || fields[1] != fmt.Sprintf("v=%d", argon2.Version) {
+			return p, nil, nil, ErrInvalidPasswordHash
+		}
+		fields = append(fields[:1], fields[2:]...)
+	}
+	if len(fields) != 4 {
+		return p, nil, nil, ErrInvalidPasswordHash
+	}
+
+	params, err := parsePHCParams(fields[1])
+	if err != nil {
+		return p, nil, nil, err
+	}
+	switch p.Algorithm {
+	case PBKDF2SHA256, PBKDF2SHA512:
+		p.Iterations = params["i"]
+	case Scrypt:
+		p.LogN, p.BlockSize, p.Parallelism = params["ln"], params["r"], params["p"]
+	case Argon2id:
+		p.Memory, p.Iterations, p.Parallelism = uint32(params["m"]), params["t"], params["p"]
+	default:
+		return p, nil, nil, fmt.Errorf("%w: %s", ErrUnknownAlgorithm, p.Algorithm)
+	}
+
+	salt, err := b64.DecodeString(fields[2])
+	if err != nil {
+		return p, nil, nil, ErrInvalidPasswordHash
+	}
+	key, err := b64.DecodeString(fields[3])
+	if err != nil || len(key) == 0 {
+		return p, nil, nil, ErrInvalidPasswordHash
+	}
+	p.SaltLen, p.KeyLen = len(salt), len(key)
+	return p, salt, key, nil
+}
+
+// parsePHCParams parses comma separated 'name=value' list of PHC string format.
+func parsePHCParams(s string) (map[string]int, error) {
+	ret := make(map[string]int)
+	for _, kv := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			return nil, ErrInvalidPasswordHash
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, ErrInvalidPasswordHash
+		}
+		ret[k] = n
+	}
+	return ret, nil
+}
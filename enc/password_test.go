@@ -0,0 +1,115 @@
+package enc_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/marrbor/goutil/enc"
+	"github.com/stretchr/testify/assert"
+)
+
+// cheap parameters not to slow tests down.
+var testPasswordParams = []enc.PasswordParams{
+	{Algorithm: enc.PBKDF2SHA256, Iterations: 1000, SaltLen: 16, KeyLen: 32},
+	{Algorithm: enc.PBKDF2SHA512, Iterations: 1000, SaltLen: 16, KeyLen: 64},
+	{Algorithm: enc.Scrypt, LogN: 10, BlockSize: 8, Parallelism: 1, SaltLen: 16, KeyLen: 32},
+	{Algorithm: enc.Argon2id, Iterations: 1, Memory: 1024, Parallelism: 2, SaltLen: 16, KeyLen: 32},
+}
+
+func TestHashPasswordWith(t *testing.T) {
+	for _, p := range testPasswordParams {
+		h, err := enc.HashPasswordWith("abcdefg", p)
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(h, "$"+string(p.Algorithm)+"$"), h)
+
+		ok, err := enc.VerifyPassword("abcdefg", h)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		ok, err = enc.VerifyPassword("abcdefh", h)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+
+		// salt is random.
+		h2, err := enc.HashPasswordWith("abcdefg", p)
+		assert.NoError(t, err)
+		assert.NotEqual(t, h, h2)
+
+		assert.False(t, enc.NeedsRehash(h, p))
+		assert.True(t, enc.NeedsRehash(h, enc.DefaultPasswordParams))
+	}
+}
+
+func TestHashPassword(t *testing.T) {
+	h, err := enc.HashPassword("abcdefg")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(h, "$argon2id$v=19$m=19456,t=2,p=1$"), h)
+	assert.False(t, enc.NeedsRehash(h, enc.DefaultPasswordParams))
+}
+
+func TestVerifyPassword_KnownHash(t *testing.T) {
+	// example hash of the Argon2 reference implementation.
+	ok, err := enc.VerifyPassword("password", "$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestVerifyPassword_Legacy(t *testing.T) {
+	legacy := enc.Encrypt256Password("abcdefg")
+	ok, err := enc.VerifyPassword("abcdefg", legacy)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = enc.VerifyPassword("abcdefh", legacy)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.True(t, enc.NeedsRehash(legacy, enc.DefaultPasswordParams))
+}
+
+func TestVerifyPassword_Invalid(t *testing.T) {
+	for _, h := range []string{
+		"",
+		"abc",
+		"$pbkdf2-sha256$i=1000$c2FsdA",
+		"$pbkdf2-sha256$i=x$c2FsdA$a2V5",
+		"$argon2id$v=16$m=1024,t=1,p=1$c2FsdA$a2V5",
+		"$scrypt$ln=10,r=8,p=1$!!!$a2V5",
+		"$pbkdf2-sha256$i=0$c2FsdA$a2V5",
+		"$pbkdf2-sha512$k=1$c2FsdA$a2V5",
+		"$scrypt$ln=0,r=8,p=1$c2FsdA$a2V5",
+		"$scrypt$ln=4,r=0,p=1$c2FsdA$a2V5",
+		"$scrypt$ln=4,r=8,p=0$c2FsdA$a2V5",
+		"$scrypt$ln=4,r=8$c2FsdA$a2V5",
+		"$scrypt$ln=4,r=1073741824,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=1024,t=0,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=1024,t=1,p=0$c2FsdA$a2V5",
+		"$argon2id$v=19$m=1024,t=1,p=256$c2FsdA$a2V5",
+		"$argon2id$v=19$m=7,t=1,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=4194305,t=1,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=1024,t=1025,p=1$c2FsdA$a2V5",
+	} {
+		_, err := enc.VerifyPassword("abcdefg", h)
+		assert.True(t, errors.Is(err, enc.ErrInvalidPasswordHash), h)
+		assert.True(t, enc.NeedsRehash(h, enc.DefaultPasswordParams))
+	}
+
+	_, err := enc.VerifyPassword("abcdefg", "$md5$i=1$c2FsdA$a2V5")
+	assert.True(t, errors.Is(err, enc.ErrUnknownAlgorithm))
+
+	_, err = enc.HashPasswordWith("abcdefg", enc.PasswordParams{Algorithm: "md5", SaltLen: 16, KeyLen: 16})
+	assert.True(t, errors.Is(err, enc.ErrUnknownAlgorithm))
+
+	// invalid parameters are rejected instead of panicking.
+	for _, p := range []enc.PasswordParams{
+		{Algorithm: enc.PBKDF2SHA256, SaltLen: 16, KeyLen: 32},
+		{Algorithm: enc.Scrypt, LogN: 4, BlockSize: 8, SaltLen: 16, KeyLen: 32},
+		{Algorithm: enc.Scrypt, LogN: 4, Parallelism: 1, SaltLen: 16, KeyLen: 32},
+		{Algorithm: enc.Argon2id, Iterations: 1, Parallelism: 1, SaltLen: 16, KeyLen: 32},
+		{Algorithm: enc.Argon2id, Iterations: 1, Memory: 1 << 30, Parallelism: 1, SaltLen: 16, KeyLen: 32},
+	} {
+		_, err = enc.HashPasswordWith("abcdefg", p)
+		assert.True(t, errors.Is(err, enc.ErrInvalidPasswordHash), "%+v", p)
+	}
+}
@@ -0,0 +1,216 @@
+package enc
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	// StreamVersion is the version of envelope generated by NewSealWriter.
+	StreamVersion = 2
+
+	// StreamSegmentSize is the plaintext size of each encrypted segment of a stream.
+	StreamSegmentSize = 64 << 10
+
+	// streamPrefixSize is the size of random nonce prefix. The rest of nonce is 4 bytes counter and 1 byte flag.
+	streamPrefixSize = 7
+)
+
+// ErrTruncated is returned when an encrypted stream ends before its last segment.
+var ErrTruncated = errors.New("encrypted stream truncated")
+
+// streamNonce returns nonce for given segment.
+func streamNonce(prefix []byte, counter uint32, last bool) []byte {
+	nonce := make([]byte, streamPrefixSize+5)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[streamPrefixSize:], counter)
+	if last {
+		nonce[len(nonce)-1] = 1
+	}
+	return nonce
+}
+
+type (
+	// sealWriter encrypts written data segment by segment.
+	sealWriter struct {
+		w       io.Writer
+		aead    cipher.AEAD
+		ad      []byte
+		prefix  []byte
+		counter uint32
+		buf     []byte
+		closed  bool
+	}
+
+	// openReader decrypts a stream generated by sealWriter.
+	openReader struct {
+		r       *bufio.Reader
+		aead    cipher.AEAD
+		ad      []byte
+		prefix  []byte
+		counter uint32
+		seg     []byte
+		buf     []byte // decrypted data not read yet.
+		done    bool
+		err     error
+	}
+)
+
+// NewSealWriter returns a writer that encrypts data written to it with given key and writes it to w.
+// Data is split into StreamSegmentSize segments that are authenticated separately, and reordering, removal and
+// truncation of segments are detected on decryption. Close has to be called to write the last segment; it does
+// not close w.
+func NewSealWriter(w io.Writer, k *Key, ad []byte) (io.WriteCloser, error) {
+	a, err := k.aead()
+	if err != nil {
+		return nil, err
+	}
+	header := envelopeHeader(StreamVersion, k)
+	prefix := make([]byte, streamPrefixSize)
+	if _, err := rand.Read(prefix); err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(append(header, prefix...)); err != nil {
+		return nil, err
+	}
+	return &sealWriter{
+		w:      w,
+		aead:   a,
+		ad:     append(header, ad...),
+		prefix: prefix,
+		buf:    make([]byte, 0, StreamSegmentSize),
+	}, nil
+}
+
+// flush encrypts buffered data and writes it as a segment.
+func (s *sealWriter) flush(last bool) error {
+	if s.counter == ^uint32(0) {
+		return errors.New("encrypted stream too long")
+	}
+	out := s.aead.Seal(nil, streamNonce(s.prefix, s.counter, last), s.buf, s.ad)
+	s.counter++
+	s.buf = s.buf[:0]
+	_, err := s.w.Write(out)
+	return err
+}
+
+// Write encrypts given data. The last segment is buffered until Close.
+func (s *sealWriter) Write(p []byte) (int, error) {
+	if s.closed {
+		return 0, errors.New("write to closed seal writer")
+	}
+	n := 0
+	for len(p) > 0 {
+		// flush full segment only when more data comes since the last segment has to be marked.
+		if len(s.buf) == StreamSegmentSize {
+			if err := s.flush(false); err != nil {
+				return n, err
+			}
+		}
+		c := copy(s.buf[len(s.buf):StreamSegmentSize], p)
+		s.buf = s.buf[:len(s.buf)+c]
+		p = p[c:]
+		n += c
+	}
+	return n, nil
+}
+
+// Close writes the last segment.
+func (s *sealWriter) Close() error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	return s.flush(true)
+}
+
+// NewOpenReader returns a reader that decrypts a stream generated by NewSealWriter from r.
+// Read returns ErrDecrypt when the stream is tampered and ErrTruncated when the stream ends unexpectedly.
+func NewOpenReader(r io.Reader, k *Key, ad []byte) (io.Reader, error) {
+	a, err := k.aead()
+	if err != nil {
+		return nil, err
+	}
+	br := bufio.NewReaderSize(r, StreamSegmentSize+a.Overhead()+1)
+
+	head := make([]byte, 3)
+	if _, err := io.ReadFull(br, head); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEnvelope, err)
+	}
+	if head[0] != StreamVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidEnvelope, head[0])
+	}
+	rest := make([]byte, int(head[2])+streamPrefixSize)
+	if _, err := io.ReadFull(br, rest); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEnvelope, err)
+	}
+	header := append(head, rest[:head[2]]...)
+	if string(header[3:]) != k.ID || AEADAlgorithm(head[1]) != k.Algorithm {
+		return nil, fmt.Errorf("%w: sealed with %q (%v)", ErrKeyMismatch, header[3:], AEADAlgorithm(head[1]))
+	}
+	return &openReader{
+		r:      br,
+		aead:   a,
+		ad:     append(header, ad...),
+		prefix: rest[head[2]:],
+		seg:    make([]byte, StreamSegmentSize+a.Overhead()),
+	}, nil
+}
+
+// next reads and decrypts next segment.
+func (o *openReader) next() error {
+	n, err := io.ReadFull(o.r, o.seg)
+	switch {
+	case errors.Is(err, io.EOF):
+		return ErrTruncated
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		// short segment is the last one.
+	case err != nil:
+		return err
+	}
+	last := n < len(o.seg)
+	if !last {
+		if _, err := o.r.Peek(1); errors.Is(err, io.EOF) {
+			last = true
+		} else if err != nil {
+			return err
+		}
+	}
+	pt, err := o.aead.Open(o.buf[:0], streamNonce(o.prefix, o.counter, last), o.seg[:n], o.ad)
+	if err != nil {
+		if last {
+			// a non-last segment at the end means truncated stream.
+			if _, e := o.aead.Open(nil, streamNonce(o.prefix, o.counter, false), o.seg[:n], o.ad); e == nil {
+				return ErrTruncated
+			}
+		}
+		return ErrDecrypt
+	}
+	o.counter++
+	o.buf = pt
+	o.done = last
+	return nil
+}
+
+// Read reads decrypted data.
+func (o *openReader) Read(p []byte) (int, error) {
+	for len(o.buf) == 0 {
+		if o.err != nil {
+			return 0, o.err
+		}
+		if o.done {
+			return 0, io.EOF
+		}
+		if err := o.next(); err != nil {
+			o.err = err
+		}
+	}
+	n := copy(p, o.buf)
+	o.buf = o.buf[n:]
+	return n, nil
+}
@@ -0,0 +1,96 @@
+package enc_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/marrbor/goutil/enc"
+	"github.com/stretchr/testify/assert"
+)
+
+// sealStream encrypts given data with stream API.
+func sealStream(t *testing.T, k *enc.Key, data, ad []byte) []byte {
+	var buf bytes.Buffer
+	w, err := enc.NewSealWriter(&buf, k, ad)
+	assert.NoError(t, err)
+	// write in odd sized chunks.
+	for len(data) > 0 {
+		n := 1000
+		if n > len(data) {
+			n = len(data)
+		}
+		_, err = w.Write(data[:n])
+		assert.NoError(t, err)
+		data = data[n:]
+	}
+	assert.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestSealWriter(t *testing.T) {
+	for _, alg := range aeadAlgorithms {
+		k, err := enc.NewKey("stream", alg)
+		assert.NoError(t, err)
+		for _, size := range []int{0, 1, enc.StreamSegmentSize - 1, enc.StreamSegmentSize, 2*enc.StreamSegmentSize + 7} {
+			data := make([]byte, size)
+			_, _ = rand.Read(data)
+
+			c := sealStream(t, k, data, []byte("ad"))
+			r, err := enc.NewOpenReader(bytes.NewReader(c), k, []byte("ad"))
+			assert.NoError(t, err)
+			p, err := io.ReadAll(r)
+			assert.NoError(t, err, size)
+			assert.True(t, bytes.Equal(data, p), size)
+		}
+	}
+}
+
+func TestOpenReader_Tampered(t *testing.T) {
+	k, err := enc.NewKey("stream", enc.AES256GCM)
+	assert.NoError(t, err)
+	data := make([]byte, 2*enc.StreamSegmentSize+10)
+	c := sealStream(t, k, data, nil)
+	seg := enc.StreamSegmentSize + 16
+	head := 3 + len(k.ID) + 7
+
+	read := func(b []byte, ad []byte) error {
+		r, err := enc.NewOpenReader(bytes.NewReader(b), k, ad)
+		if err != nil {
+			return err
+		}
+		_, err = io.ReadAll(r)
+		return err
+	}
+
+	// flipped bit.
+	tc := append([]byte{}, c...)
+	tc[len(tc)-1] ^= 1
+	assert.True(t, errors.Is(read(tc, nil), enc.ErrDecrypt))
+
+	// wrong associated data.
+	assert.True(t, errors.Is(read(c, []byte("x")), enc.ErrDecrypt))
+
+	// truncated at segment boundary.
+	assert.True(t, errors.Is(read(c[:head+seg], nil), enc.ErrTruncated))
+	assert.True(t, errors.Is(read(c[:head], nil), enc.ErrTruncated))
+
+	// swapped segments.
+	sw := append([]byte{}, c[:head]...)
+	sw = append(sw, c[head+seg:head+2*seg]...)
+	sw = append(sw, c[head:head+seg]...)
+	sw = append(sw, c[head+2*seg:]...)
+	assert.True(t, errors.Is(read(sw, nil), enc.ErrDecrypt))
+
+	// another key.
+	k2, err := enc.NewKey("other", enc.AES256GCM)
+	assert.NoError(t, err)
+	_, err = enc.NewOpenReader(bytes.NewReader(c), k2, nil)
+	assert.True(t, errors.Is(err, enc.ErrKeyMismatch))
+
+	// broken header.
+	_, err = enc.NewOpenReader(bytes.NewReader(c[:2]), k, nil)
+	assert.True(t, errors.Is(err, enc.ErrInvalidEnvelope))
+}
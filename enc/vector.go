@@ -0,0 +1,141 @@
+package enc
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/marrbor/goutil/closer"
+)
+
+// Vector is a test vector: a record of 'Name = value' fields.
+type Vector struct {
+	Section string // name of the preceding [section] header, empty when none.
+	Line    int    // line number where the record starts.
+	Fields  map[string]string
+}
+
+// ReadVectors parses test vectors in the format of NIST CAVP response files:
+//
+//	# comment
+//	[SHA256]
+//	Msg = 616263
+//	MD = ba7816bf...
+//
+// Records are separated by blank lines or section headers, and fields of a section header like '[L = 32]' are
+// copied into every record of the section. A value may be quoted by double quotes to keep spaces.
+func ReadVectors(r io.Reader) ([]Vector, error) {
+	var (
+		ret     []Vector
+		section string
+		common  map[string]string
+		cur     *Vector
+	)
+	flush := func() {
+		if cur != nil {
+			ret = append(ret, *cur)
+			cur = nil
+		}
+	}
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case line == "":
+			flush()
+		case strings.HasPrefix(line, "#"):
+		case strings.HasPrefix(line, "["):
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: unterminated section %q", n, line)
+			}
+			flush()
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			common = map[string]string{}
+			if name, value, ok := strings.Cut(section, "="); ok {
+				common[strings.TrimSpace(name)] = strings.TrimSpace(value)
+			}
+		default:
+			name, value, ok := strings.Cut(line, "=")
+			if !ok {
+				return nil, fmt.Errorf("line %d: %q is not 'name = value'", n, line)
+			}
+			name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+			if strings.HasPrefix(value, `"`) {
+				s, err := strconv.Unquote(value)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %w", n, err)
+				}
+				value = s
+			}
+			if cur == nil {
+				cur = &Vector{Section: section, Line: n, Fields: map[string]string{}}
+				for k, v := range common {
+					cur.Fields[k] = v
+				}
+			}
+			cur.Fields[name] = value
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+	return ret, nil
+}
+
+// LoadVectors reads test vectors from given file. See ReadVectors.
+func LoadVectors(path string) ([]Vector, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close(f)
+	return ReadVectors(f)
+}
+
+// Has returns whether the vector has given field or not.
+func (v Vector) Has(name string) bool {
+	_, ok := v.Fields[name]
+	return ok
+}
+
+// String returns the value of given field, empty string when it is missing.
+func (v Vector) String(name string) string {
+	return v.Fields[name]
+}
+
+// Hex returns hex decoded value of given field. Empty value and "00" with 'Len = 0' field of NIST files are
+// returned as empty.
+func (v Vector) Hex(name string) ([]byte, error) {
+	s, ok := v.Fields[name]
+	if !ok {
+		return nil, fmt.Errorf("line %d: no field %s", v.Line, name)
+	}
+	if v.Fields["Len"] == "0" && s == "00" {
+		return []byte{}, nil
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("line %d: field %s: %w", v.Line, name, err)
+	}
+	return b, nil
+}
+
+// Int returns decimal value of given field.
+func (v Vector) Int(name string) (int64, error) {
+	s, ok := v.Fields[name]
+	if !ok {
+		return 0, fmt.Errorf("line %d: no field %s", v.Line, name)
+	}
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("line %d: field %s: %w", v.Line, name, err)
+	}
+	return i, nil
+}
@@ -0,0 +1,209 @@
+package enc_test
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marrbor/goutil/enc"
+	"github.com/stretchr/testify/assert"
+)
+
+// loadVectors returns test vectors of given file in testdata.
+func loadVectors(t *testing.T, name string) []enc.Vector {
+	t.Helper()
+	vs, err := enc.LoadVectors(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vs) == 0 {
+		t.Fatalf("no vector in %s", name)
+	}
+	return vs
+}
+
+// mustHex returns hex decoded field of given vector.
+func mustHex(t *testing.T, v enc.Vector, name string) []byte {
+	t.Helper()
+	b, err := v.Hex(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// mustInt returns decimal field of given vector.
+func mustInt(t *testing.T, v enc.Vector, name string) int64 {
+	t.Helper()
+	i, err := v.Int(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return i
+}
+
+func TestReadVectors(t *testing.T) {
+	src := `# comment
+Count = 0
+Msg = 00
+
+[L = 32]
+Count = 1
+Msg = "a b"
+
+Count = 2
+Msg = ff
+[other]
+Msg = 0102
+`
+	vs, err := enc.ReadVectors(strings.NewReader(src))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 4, len(vs))
+
+	assert.EqualValues(t, "", vs[0].Section)
+	assert.EqualValues(t, 2, vs[0].Line)
+	assert.EqualValues(t, "0", vs[0].String("Count"))
+
+	assert.EqualValues(t, "L = 32", vs[1].Section)
+	assert.EqualValues(t, "32", vs[1].String("L"))
+	assert.EqualValues(t, "a b", vs[1].String("Msg"))
+	assert.EqualValues(t, 1, mustInt(t, vs[1], "Count"))
+
+	assert.EqualValues(t, "32", vs[2].String("L"))
+	assert.EqualValues(t, []byte{0xff}, mustHex(t, vs[2], "Msg"))
+
+	assert.EqualValues(t, "other", vs[3].Section)
+	assert.EqualValues(t, 12, vs[3].Line)
+	assert.False(t, vs[3].Has("L"))
+	assert.EqualValues(t, []byte{1, 2}, mustHex(t, vs[3], "Msg"))
+	_, err = vs[3].Hex("Count")
+	assert.Error(t, err)
+	_, err = vs[1].Hex("Msg")
+	assert.Error(t, err)
+	_, err = vs[1].Int("Msg")
+	assert.Error(t, err)
+
+	// empty message of NIST files.
+	vs, err = enc.ReadVectors(strings.NewReader("Len = 0\nMsg = 00\n"))
+	assert.NoError(t, err)
+	assert.EqualValues(t, []byte{}, mustHex(t, vs[0], "Msg"))
+
+	for _, bad := range []string{"[open", "no value", `Msg = "unterminated`} {
+		_, err = enc.ReadVectors(strings.NewReader(bad))
+		assert.Error(t, err, bad)
+	}
+	_, err = enc.LoadVectors(filepath.Join("testdata", "missing.rsp"))
+	assert.Error(t, err)
+}
+
+func TestVectors_Hash(t *testing.T) {
+	for _, v := range loadVectors(t, "hash.rsp") {
+		alg, err := enc.ParseHashAlgorithm(v.Section)
+		assert.NoError(t, err)
+		got := enc.Sum(alg, mustHex(t, v, "Msg"))
+		assert.EqualValues(t, v.String("MD"), got.Hex(), "%s line %d", v.Section, v.Line)
+	}
+}
+
+func TestVectors_HMAC(t *testing.T) {
+	algs := map[string]enc.HMACAlgorithm{"SHA256": enc.HMACSHA256, "SHA512": enc.HMACSHA512}
+	for _, v := range loadVectors(t, "hmac.rsp") {
+		alg, ok := algs[v.Section]
+		assert.True(t, ok, v.Section)
+		key, msg, mac := mustHex(t, v, "Key"), mustHex(t, v, "Msg"), mustHex(t, v, "Mac")
+		assert.EqualValues(t, mac, enc.SignHMAC(alg, key, msg), "line %d", v.Line)
+		assert.True(t, enc.VerifyHMAC(alg, key, msg, mac))
+		mac[0] ^= 1
+		assert.False(t, enc.VerifyHMAC(alg, key, msg, mac))
+	}
+}
+
+func TestVectors_OTP(t *testing.T) {
+	algs := map[string]enc.OTPAlgorithm{"SHA1": enc.OTPSHA1, "SHA256": enc.OTPSHA256, "SHA512": enc.OTPSHA512}
+	for _, v := range loadVectors(t, "otp.rsp") {
+		want := v.String("OTP")
+		o := &enc.OTP{Secret: mustHex(t, v, "Secret"), Algorithm: algs[v.String("Algorithm")], Digits: len(want)}
+		var (
+			got string
+			err error
+		)
+		if v.Section == "HOTP" {
+			got, err = o.HOTP(uint64(mustInt(t, v, "Counter")))
+		} else {
+			o.Period = time.Duration(mustInt(t, v, "Period")) * time.Second
+			got, err = o.TOTPAt(time.Unix(mustInt(t, v, "Time"), 0))
+		}
+		assert.NoError(t, err)
+		assert.EqualValues(t, want, got, "line %d", v.Line)
+	}
+}
+
+func TestVectors_Password(t *testing.T) {
+	b64 := base64.RawStdEncoding
+	for _, v := range loadVectors(t, "password.rsp") {
+		var params string
+		switch v.Section {
+		case "pbkdf2-sha256":
+			params = fmt.Sprintf("i=%d", mustInt(t, v, "Iterations"))
+		case "scrypt":
+			params = fmt.Sprintf("ln=%d,r=%d,p=%d", mustInt(t, v, "LogN"), mustInt(t, v, "BlockSize"), mustInt(t, v, "Parallelism"))
+		default:
+			t.Fatalf("unknown section %s", v.Section)
+		}
+		dk := mustHex(t, v, "DK")
+		phc := fmt.Sprintf("$%s$%s$%s$%s", v.Section, params, b64.EncodeToString(mustHex(t, v, "Salt")), b64.EncodeToString(dk))
+		ok, err := enc.VerifyPassword(v.String("Password"), phc)
+		assert.NoError(t, err)
+		assert.True(t, ok, "line %d", v.Line)
+		ok, err = enc.VerifyPassword(v.String("Password")+"x", phc)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	}
+}
+
+func TestVectors_Codec(t *testing.T) {
+	for _, v := range loadVectors(t, "codec.rsp") {
+		c, ok := codecs[v.Section]
+		assert.True(t, ok, v.Section)
+		msg, want := mustHex(t, v, "Msg"), v.String("Enc")
+		assert.EqualValues(t, want, c.EncodeToString(msg), "line %d", v.Line)
+		got, err := c.DecodeString(want)
+		assert.NoError(t, err)
+		assert.EqualValues(t, hex.EncodeToString(msg), hex.EncodeToString(got))
+	}
+}
+
+func TestVectors_Envelope(t *testing.T) {
+	for _, v := range loadVectors(t, "envelope.rsp") {
+		alg, err := enc.ParseAEADAlgorithm(v.String("Algorithm"))
+		assert.NoError(t, err)
+		k := &enc.Key{ID: v.String("KeyID"), Algorithm: alg, Secret: mustHex(t, v, "Secret")}
+		envelope, ad := mustHex(t, v, "Envelope"), mustHex(t, v, "AD")
+		pt, err := enc.Open(k, envelope, ad)
+		assert.NoError(t, err, "line %d", v.Line)
+		assert.EqualValues(t, hex.EncodeToString(mustHex(t, v, "Plaintext")), hex.EncodeToString(pt))
+
+		envelope[len(envelope)-1] ^= 1
+		_, err = enc.Open(k, envelope, ad)
+		assert.Error(t, err)
+	}
+}
+
+func TestVectors_JWS(t *testing.T) {
+	for _, v := range loadVectors(t, "jws.rsp") {
+		key, err := base64.RawURLEncoding.DecodeString(v.String("Key"))
+		assert.NoError(t, err)
+		now := time.Unix(mustInt(t, v, "Now"), 0)
+		ver := &enc.JWTVerifier{Algorithm: enc.JWTAlgorithm(v.Section), Key: key, Issuer: v.String("Issuer"), Now: func() time.Time { return now }}
+		var claims map[string]interface{}
+		assert.NoError(t, ver.Verify(v.String("Token"), &claims), "line %d", v.Line)
+		assert.EqualValues(t, v.String("Issuer"), claims["iss"])
+
+		token := v.String("Token")
+		assert.Error(t, ver.Verify(token[:len(token)-2]+"AA", nil))
+	}
+}
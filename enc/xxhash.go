@@ -0,0 +1,120 @@
+package enc
+
+import (
+	"encoding/binary"
+	"hash"
+	"math/bits"
+)
+
+// xxHash64 primes.
+const (
+	xxPrime1 uint64 = 11400714785074694791
+	xxPrime2 uint64 = 14029467366897019727
+	xxPrime3 uint64 = 1609587929392839161
+	xxPrime4 uint64 = 9650029242287828579
+	xxPrime5 uint64 = 2870177450012600261
+)
+
+// xxh64 is hash.Hash64 of xxHash64 (seed 0). refer: https://github.com/Cyan4973/xxHash/blob/dev/doc/xxhash_spec.md
+type xxh64 struct {
+	v     [4]uint64
+	total uint64
+	mem   [32]byte
+	n     int // bytes in mem.
+}
+
+// newXXH64 returns new xxHash64.
+func newXXH64() hash.Hash64 {
+	x := &xxh64{}
+	x.Reset()
+	return x
+}
+
+func xxRound(acc, input uint64) uint64 {
+	acc += input * xxPrime2
+	acc = bits.RotateLeft64(acc, 31)
+	return acc * xxPrime1
+}
+
+func xxMergeRound(acc, val uint64) uint64 {
+	acc ^= xxRound(0, val)
+	return acc*xxPrime1 + xxPrime4
+}
+
+func (x *xxh64) Reset() {
+	p1, p2 := xxPrime1, xxPrime2 // variables to let additions wrap around.
+	x.v = [4]uint64{p1 + p2, p2, 0, -p1}
+	x.total = 0
+	x.n = 0
+}
+
+func (x *xxh64) Size() int      { return 8 }
+func (x *xxh64) BlockSize() int { return 32 }
+
+// stripe consumes a 32 bytes stripe.
+func (x *xxh64) stripe(b []byte) {
+	x.v[0] = xxRound(x.v[0], binary.LittleEndian.Uint64(b[0:]))
+	x.v[1] = xxRound(x.v[1], binary.LittleEndian.Uint64(b[8:]))
+	x.v[2] = xxRound(x.v[2], binary.LittleEndian.Uint64(b[16:]))
+	x.v[3] = xxRound(x.v[3], binary.LittleEndian.Uint64(b[24:]))
+}
+
+func (x *xxh64) Write(b []byte) (int, error) {
+	l := len(b)
+	x.total += uint64(l)
+	if x.n > 0 {
+		c := copy(x.mem[x.n:], b)
+		x.n += c
+		b = b[c:]
+		if x.n < 32 {
+			return l, nil
+		}
+		x.stripe(x.mem[:])
+		x.n = 0
+	}
+	for ; len(b) >= 32; b = b[32:] {
+		x.stripe(b)
+	}
+	x.n = copy(x.mem[:], b)
+	return l, nil
+}
+
+func (x *xxh64) Sum64() uint64 {
+	var h uint64
+	if x.total >= 32 {
+		h = bits.RotateLeft64(x.v[0], 1) + bits.RotateLeft64(x.v[1], 7) +
+			bits.RotateLeft64(x.v[2], 12) + bits.RotateLeft64(x.v[3], 18)
+		for _, v := range x.v {
+			h = xxMergeRound(h, v)
+		}
+	} else {
+		h = xxPrime5
+	}
+	h += x.total
+
+	b := x.mem[:x.n]
+	for ; len(b) >= 8; b = b[8:] {
+		h ^= xxRound(0, binary.LittleEndian.Uint64(b))
+		h = bits.RotateLeft64(h, 27)*xxPrime1 + xxPrime4
+	}
+	if len(b) >= 4 {
+		h ^= uint64(binary.LittleEndian.Uint32(b)) * xxPrime1
+		h = bits.RotateLeft64(h, 23)*xxPrime2 + xxPrime3
+		b = b[4:]
+	}
+	for _, c := range b {
+		h ^= uint64(c) * xxPrime5
+		h = bits.RotateLeft64(h, 11) * xxPrime1
+	}
+
+	h ^= h >> 33
+	h *= xxPrime2
+	h ^= h >> 29
+	h *= xxPrime3
+	h ^= h >> 32
+	return h
+}
+
+func (x *xxh64) Sum(b []byte) []byte {
+	return binary.BigEndian.AppendUint64(b, x.Sum64())
+}
@@ -0,0 +1,221 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/marrbor/goutil/enc"
+)
+
+// ErrDuplicateKey is returned when an object to be canonicalized has the same key twice.
+var ErrDuplicateKey = errors.New("json: duplicate object key")
+
+// Canonicalize returns RFC 8785 JSON Canonicalization Scheme (JCS) form of given JSON document: no white spaces,
+// object members sorted by UTF-16 code units of their keys, numbers formatted like ECMAScript and strings escaped
+// minimally. The result is byte-identical across implementations, so that it can be hashed and signed. Duplicate
+// keys are rejected with ErrDuplicateKey, and numbers out of IEEE 754 double range are rejected.
+func Canonicalize(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	v, err := readCanonical(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("%w after offset %d", ErrTrailingData, dec.InputOffset())
+	}
+	var buf bytes.Buffer
+	if err := writeCanonical(&buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// MarshalCanonical returns JCS form of JSON of given value. See Canonicalize.
+func MarshalCanonical(v interface{}) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return Canonicalize(b)
+}
+
+// CanonicalDigest returns the digest of JCS form of JSON of given value. It returns enc.ErrUnknownAlgorithm when the
+// algorithm is not available.
+func CanonicalDigest(alg enc.HashAlgorithm, v interface{}) (enc.Digest, error) {
+	if !alg.Available() {
+		return nil, fmt.Errorf("%w: %s", enc.ErrUnknownAlgorithm, alg)
+	}
+	b, err := MarshalCanonical(v)
+	if err != nil {
+		return nil, err
+	}
+	return enc.Sum(alg, b), nil
+}
+
+// CanonicalHMAC returns HMAC of JCS form of JSON of given value with given key.
+func CanonicalHMAC(alg enc.HMACAlgorithm, key []byte, v interface{}) ([]byte, error) {
+	b, err := MarshalCanonical(v)
+	if err != nil {
+		return nil, err
+	}
+	return enc.SignHMAC(alg, key, b), nil
+}
+
+// readCanonical reads a value from given decoder, rejecting duplicate keys.
+func readCanonical(dec *json.Decoder) (interface{}, error) {
+	t, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t {
+	case json.Delim('{'):
+		obj := map[string]interface{}{}
+		for dec.More() {
+			kt, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			k := kt.(string)
+			if _, ok := obj[k]; ok {
+				return nil, fmt.Errorf("%w %q", ErrDuplicateKey, k)
+			}
+			if obj[k], err = readCanonical(dec); err != nil {
+				return nil, err
+			}
+		}
+		_, err := dec.Token() // '}'
+		return obj, err
+	case json.Delim('['):
+		arr := []interface{}{}
+		for dec.More() {
+			e, err := readCanonical(dec)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, e)
+		}
+		_, err := dec.Token() // ']'
+		return arr, err
+	}
+	return t, nil
+}
+
+// writeCanonical writes JCS form of given decoded value.
+func writeCanonical(buf *bytes.Buffer, v interface{}) error {
+	switch x := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(x))
+	case json.Number:
+		s, err := canonicalNumber(x)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(s)
+	case string:
+		writeCanonicalString(buf, x)
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, e := range x {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonical(buf, e); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]interface{}:
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool { return lessUTF16(keys[i], keys[j]) })
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeCanonicalString(buf, k)
+			buf.WriteByte(':')
+			if err := writeCanonical(buf, x[k]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("json: can not canonicalize %T", v)
+	}
+	return nil
+}
+
+// canonicalNumber returns given number formatted like Number.prototype.toString of ECMAScript.
+func canonicalNumber(n json.Number) (string, error) {
+	f, err := strconv.ParseFloat(string(n), 64)
+	if err != nil || math.IsInf(f, 0) {
+		return "", fmt.Errorf("json: number %s is out of range", n)
+	}
+	if f == 0 {
+		return "0", nil // also -0.
+	}
+	if a := math.Abs(f); 1e-6 <= a && a < 1e21 {
+		return strconv.FormatFloat(f, 'f', -1, 64), nil
+	}
+	// Go formats exponent like "e-07", ECMAScript like "e-7".
+	mant, exp, _ := strings.Cut(strconv.FormatFloat(f, 'e', -1, 64), "e")
+	return mant + "e" + exp[:1] + strings.TrimLeft(exp[1:], "0"), nil
+}
+
+// writeCanonicalString writes given string escaping only '"', '\\' and control characters.
+func writeCanonicalString(buf *bytes.Buffer, s string) {
+	const hex = "0123456789abcdef"
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hex[r>>4])
+				buf.WriteByte(hex[r&0xf])
+				continue
+			}
+			buf.WriteRune(r)
+		}
+	}
+	buf.WriteByte('"')
+}
+
+// lessUTF16 compares given strings by their UTF-16 code units.
+func lessUTF16(a, b string) bool {
+	x, y := utf16.Encode([]rune(a)), utf16.Encode([]rune(b))
+	for i := 0; i < len(x) && i < len(y); i++ {
+		if x[i] != y[i] {
+			return x[i] < y[i]
+		}
+	}
+	return len(x) < len(y)
+}
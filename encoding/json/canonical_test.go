@@ -0,0 +1,115 @@
+package json_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/marrbor/goutil/enc"
+	"github.com/marrbor/goutil/encoding/json"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonicalize(t *testing.T) {
+	// RFC 8785 section 3.2.2.
+	in := `{
+	  "numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+	  "string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+	  "literals": [null, true, false]
+	}`
+	b, err := json.Canonicalize([]byte(in))
+	assert.NoError(t, err)
+	assert.EqualValues(t, `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`, string(b))
+
+	// RFC 8785 section 3.2.3: keys are sorted by UTF-16 code units.
+	in = `{"\u20ac":"Euro Sign","\r":"Carriage Return","\ufb33":"Hebrew Letter Dalet With Dagesh","1":"One",` +
+		`"\ud83d\ude00":"Emoji: Grinning Face","\u0080":"Control","\u00f6":"Latin Small Letter O With Diaeresis"}`
+	b, err = json.Canonicalize([]byte(in))
+	assert.NoError(t, err)
+	assert.EqualValues(t, "{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\",\"ö\":\"Latin Small Letter O With Diaeresis\","+
+		"\"€\":\"Euro Sign\",\"😀\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}", string(b))
+
+	b, err = json.Canonicalize([]byte(` {"b" : [ {}, [] , "<&>\u2028" ], "a":{"z":1,"y":-0}} `))
+	assert.NoError(t, err)
+	assert.EqualValues(t, "{\"a\":{\"y\":0,\"z\":1},\"b\":[{},[],\"<&>\u2028\"]}", string(b))
+
+	_, err = json.Canonicalize([]byte(`{"a":1,"a":2}`))
+	assert.True(t, errors.Is(err, json.ErrDuplicateKey))
+	_, err = json.Canonicalize([]byte(`[1e400]`))
+	assert.Error(t, err)
+	_, err = json.Canonicalize([]byte(`{} []`))
+	assert.True(t, errors.Is(err, json.ErrTrailingData))
+	_, err = json.Canonicalize([]byte(`{"a":}`))
+	assert.Error(t, err)
+}
+
+func TestMarshalCanonical_Numbers(t *testing.T) {
+	// RFC 8785 appendix B.
+	tests := map[uint64]string{
+		0x0000000000000000: "0",
+		0x8000000000000000: "0",
+		0x0000000000000001: "5e-324",
+		0x8000000000000001: "-5e-324",
+		0x7fefffffffffffff: "1.7976931348623157e+308",
+		0xffefffffffffffff: "-1.7976931348623157e+308",
+		0x4340000000000000: "9007199254740992",
+		0xc340000000000000: "-9007199254740992",
+		0x4430000000000000: "295147905179352830000",
+		0x44b52d02c7e14af5: "9.999999999999997e+22",
+		0x44b52d02c7e14af6: "1e+23",
+		0x44b52d02c7e14af7: "1.0000000000000001e+23",
+		0x444b1ae4d6e2ef4e: "999999999999999700000",
+		0x444b1ae4d6e2ef4f: "999999999999999900000",
+		0x444b1ae4d6e2ef50: "1e+21",
+		0x3eb0c6f7a0b5ed8c: "9.999999999999997e-7",
+		0x3eb0c6f7a0b5ed8d: "0.000001",
+		0x41b3de4355555553: "333333333.3333332",
+		0x41b3de4355555554: "333333333.33333325",
+		0x41b3de4355555555: "333333333.3333333",
+		0x41b3de4355555556: "333333333.3333334",
+		0x41b3de4355555557: "333333333.33333343",
+		0xbecbf647612f3696: "-0.0000033333333333333333",
+		0x43143ff3c1cb0959: "1424953923781206.2",
+	}
+	for bits, want := range tests {
+		b, err := json.MarshalCanonical(math.Float64frombits(bits))
+		assert.NoError(t, err)
+		assert.EqualValues(t, want, string(b), "%016x", bits)
+	}
+}
+
+func TestMarshalCanonical(t *testing.T) {
+	type payload struct {
+		Z    string            `json:"z"`
+		A    int64             `json:"a"`
+		Meta map[string]string `json:"meta"`
+	}
+	p := payload{Z: "<tag>", A: 1, Meta: map[string]string{"y": "1", "x": "2"}}
+	b, err := json.MarshalCanonical(p)
+	assert.NoError(t, err)
+	assert.EqualValues(t, `{"a":1,"meta":{"x":"2","y":"1"},"z":"<tag>"}`, string(b))
+
+	_, err = json.MarshalCanonical(make(chan int))
+	assert.Error(t, err)
+
+	// same digest for the same data in any key order.
+	d1, err := json.CanonicalDigest(enc.SHA256, p)
+	assert.NoError(t, err)
+	d2, err := json.CanonicalDigest(enc.SHA256, map[string]interface{}{"meta": map[string]interface{}{"x": "2", "y": "1"}, "z": "<tag>", "a": 1.0})
+	assert.NoError(t, err)
+	assert.EqualValues(t, d1, d2)
+	assert.EqualValues(t, enc.Sum(enc.SHA256, b), d1)
+
+	key := []byte("secret")
+	mac, err := json.CanonicalHMAC(enc.HMACSHA256, key, p)
+	assert.NoError(t, err)
+	assert.True(t, enc.VerifyHMAC(enc.HMACSHA256, key, b, mac))
+
+	_, err = json.CanonicalDigest(enc.HashAlgorithm(255), p)
+	assert.True(t, errors.Is(err, enc.ErrUnknownAlgorithm))
+
+	_, err = json.CanonicalDigest(enc.SHA256, make(chan int))
+	assert.Error(t, err)
+	_, err = json.CanonicalHMAC(enc.HMACSHA256, key, make(chan int))
+	assert.Error(t, err)
+}
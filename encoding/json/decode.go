@@ -0,0 +1,136 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var (
+	// ErrTooLarge is returned when the input exceeds the size given by MaxBytes.
+	ErrTooLarge = errors.New("json: input too large")
+	// ErrTrailingData is returned when something other than white spaces follows the decoded value.
+	ErrTrailingData = errors.New("json: trailing data after value")
+)
+
+// maxEmptyReads is the number of successive reads returning no data and no error tolerated at the limit, like
+// bufio.Reader does.
+const maxEmptyReads = 100
+
+type (
+	// DecodeOption is an option of DecodeReader and Decode.
+	DecodeOption func(*decodeConfig)
+
+	decodeConfig struct {
+		disallowUnknown bool
+		useNumber       bool
+		maxBytes        int64
+		skipBadLines    bool
+		reportBadLine   func(*LineError)
+		schema          *Schema
+	}
+)
+
+// DisallowUnknownFields makes decoding fail when an object has a key that does not match any field of the structure.
+func DisallowUnknownFields() DecodeOption {
+	return func(c *decodeConfig) { c.disallowUnknown = true }
+}
+
+// UseNumber makes decoding store numbers in interface{} as json.Number instead of float64 to keep their precision.
+func UseNumber() DecodeOption {
+	return func(c *decodeConfig) { c.useNumber = true }
+}
+
+// MaxBytes makes decoding fail with ErrTooLarge when the input exceeds given size. 0 means no limit.
+func MaxBytes(n int64) DecodeOption {
+	return func(c *decodeConfig) { c.maxBytes = n }
+}
+
+// limitedReader returns ErrTooLarge after reading n bytes.
+type limitedReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.n <= 0 {
+		// accept EOF just at the limit. read again while the reader returns neither data nor error.
+		var b [1]byte
+		for i := 0; i < maxEmptyReads; i++ {
+			n, err := l.r.Read(b[:])
+			if n > 0 {
+				return 0, ErrTooLarge
+			}
+			if err != nil {
+				return 0, err
+			}
+		}
+		return 0, io.ErrNoProgress
+	}
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	return n, err
+}
+
+// DecodeReader decodes one JSON value read from given reader into v. Like json.Unmarshal, data other than white
+// spaces after the value is rejected with ErrTrailingData. With ValidateSchema, the value is validated before it is
+// decoded.
+func DecodeReader(r io.Reader, v interface{}, opts ...DecodeOption) error {
+	var c decodeConfig
+	for _, o := range opts {
+		o(&c)
+	}
+	if c.schema == nil {
+		return c.decode(r, v)
+	}
+	var raw json.RawMessage
+	if err := c.decode(r, &raw); err != nil {
+		return err
+	}
+	if err := c.schema.Validate(raw); err != nil {
+		return err
+	}
+	return c.decode(bytes.NewReader(raw), v)
+}
+
+// decode decodes one JSON value read from given reader into v.
+func (c *decodeConfig) decode(r io.Reader, v interface{}) error {
+	if c.maxBytes > 0 {
+		r = &limitedReader{r: r, n: c.maxBytes}
+	}
+	dec := json.NewDecoder(r)
+	if c.disallowUnknown {
+		dec.DisallowUnknownFields()
+	}
+	if c.useNumber {
+		dec.UseNumber()
+	}
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	end := dec.InputOffset()
+	if _, err := dec.Token(); err != io.EOF {
+		if errors.Is(err, ErrTooLarge) {
+			return err
+		}
+		return fmt.Errorf("%w after offset %d", ErrTrailingData, end)
+	}
+	return nil
+}
+
+// Decode returns the value of type T decoded from given reader. See DecodeReader.
+func Decode[T any](r io.Reader, opts ...DecodeOption) (T, error) {
+	var v T
+	err := DecodeReader(r, &v, opts...)
+	return v, err
+}
+
+// UnmarshalStrict parses given JSON into v like json.Unmarshal, but rejects unknown fields.
+func UnmarshalStrict(data []byte, v interface{}) error {
+	return DecodeReader(bytes.NewReader(data), v, DisallowUnknownFields())
+}
@@ -0,0 +1,100 @@
+package json_test
+
+import (
+	stdjson "encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/marrbor/goutil/encoding/json"
+	"github.com/stretchr/testify/assert"
+)
+
+type item struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestDecodeReader(t *testing.T) {
+	var it item
+	assert.NoError(t, json.DecodeReader(strings.NewReader(` {"id":1,"name":"a","extra":true} `+"\n"), &it))
+	assert.EqualValues(t, item{ID: 1, Name: "a"}, it)
+
+	err := json.DecodeReader(strings.NewReader(`{"id":1,"extra":true}`), &it, json.DisallowUnknownFields())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "extra")
+
+	for _, s := range []string{`{"id":1}{"id":2}`, `{"id":1} x`, `{"id":1}]`} {
+		err = json.DecodeReader(strings.NewReader(s), &it)
+		assert.True(t, errors.Is(err, json.ErrTrailingData), s)
+	}
+	assert.EqualValues(t, `json: trailing data after value after offset 8`, json.DecodeReader(strings.NewReader(`{"id":1} 2`), &it).Error())
+
+	assert.Error(t, json.DecodeReader(strings.NewReader(`{"id":"1"}`), &it))
+	assert.True(t, errors.Is(json.DecodeReader(strings.NewReader(""), &it), io.EOF))
+}
+
+func TestDecodeReader_MaxBytes(t *testing.T) {
+	s := `{"id":1,"name":"abc"}`
+	var it item
+	assert.NoError(t, json.DecodeReader(strings.NewReader(s), &it, json.MaxBytes(int64(len(s)))))
+	assert.NoError(t, json.DecodeReader(strings.NewReader(s), &it, json.MaxBytes(0)))
+
+	err := json.DecodeReader(strings.NewReader(s), &it, json.MaxBytes(int64(len(s)-1)))
+	assert.True(t, errors.Is(err, json.ErrTooLarge))
+
+	// white spaces after the value count.
+	err = json.DecodeReader(strings.NewReader(s+"   "), &it, json.MaxBytes(int64(len(s)+1)))
+	assert.True(t, errors.Is(err, json.ErrTooLarge))
+
+	// reader returning neither data nor error at the limit.
+	err = json.DecodeReader(&emptyReadReader{r: strings.NewReader(s + " ")}, &it, json.MaxBytes(int64(len(s))))
+	assert.True(t, errors.Is(err, json.ErrTooLarge))
+	assert.NoError(t, json.DecodeReader(&emptyReadReader{r: strings.NewReader(s)}, &it, json.MaxBytes(int64(len(s)))))
+}
+
+// emptyReadReader returns (0, nil) before every read of underlying reader.
+type emptyReadReader struct {
+	r     io.Reader
+	empty bool
+}
+
+func (r *emptyReadReader) Read(p []byte) (int, error) {
+	r.empty = !r.empty
+	if r.empty {
+		return 0, nil
+	}
+	return r.r.Read(p)
+}
+
+func TestDecode(t *testing.T) {
+	it, err := json.Decode[item](strings.NewReader(`{"id":2,"name":"b"}`))
+	assert.NoError(t, err)
+	assert.EqualValues(t, item{ID: 2, Name: "b"}, it)
+
+	l, err := json.Decode[[]int](strings.NewReader(`[1,2,3]`))
+	assert.NoError(t, err)
+	assert.EqualValues(t, []int{1, 2, 3}, l)
+
+	_, err = json.Decode[item](strings.NewReader(`{"id":2,"x":0}`), json.DisallowUnknownFields())
+	assert.Error(t, err)
+
+	// numbers keep precision with UseNumber.
+	m, err := json.Decode[map[string]interface{}](strings.NewReader(`{"n":12345678901234567890,"f":1.10}`), json.UseNumber())
+	assert.NoError(t, err)
+	assert.EqualValues(t, stdjson.Number("12345678901234567890"), m["n"])
+	assert.EqualValues(t, stdjson.Number("1.10"), m["f"])
+
+	m, err = json.Decode[map[string]interface{}](strings.NewReader(`{"n":1}`))
+	assert.NoError(t, err)
+	assert.EqualValues(t, float64(1), m["n"])
+}
+
+func TestUnmarshalStrict(t *testing.T) {
+	var it item
+	assert.NoError(t, json.UnmarshalStrict([]byte(`{"id":3}`), &it))
+	assert.EqualValues(t, 3, it.ID)
+	assert.Error(t, json.UnmarshalStrict([]byte(`{"id":3,"x":1}`), &it))
+	assert.True(t, errors.Is(json.UnmarshalStrict([]byte(`{} {}`), &it), json.ErrTrailingData))
+}
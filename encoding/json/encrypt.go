@@ -0,0 +1,473 @@
+package json
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// EncryptTag is the struct tag that marks a field to be encrypted: `encrypt:"true"`.
+const EncryptTag = "encrypt"
+
+// Cipher encrypts and decrypts field values. *enc.KeyRing of goutil implements it.
+type Cipher interface {
+	Seal(plaintext, ad []byte) ([]byte, error)
+	Open(ciphertext, ad []byte) ([]byte, error)
+}
+
+// MarshalEncrypted returns JSON of given value like json.Marshal, but the value of every field tagged with
+// `encrypt:"true"` is encrypted by given cipher and stored as base64url string. The field name is authenticated
+// as associated data, so that encrypted values can not be swapped between fields. Other fields stay readable.
+func MarshalEncrypted(v interface{}, c Cipher) ([]byte, error) {
+	e, err := encryptValue(reflect.ValueOf(v), c)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(e)
+}
+
+// UnmarshalEncrypted parses JSON generated by MarshalEncrypted into given pointer, decrypting the fields tagged
+// with `encrypt:"true"`.
+func UnmarshalEncrypted(data []byte, v interface{}, c Cipher) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("json: UnmarshalEncrypted needs non-nil pointer, got %T", v)
+	}
+	return decryptValue(data, rv.Elem(), c)
+}
+
+// fieldInfo is a JSON field of a structure.
+type fieldInfo struct {
+	index     []int
+	name      string
+	omitEmpty bool
+	encrypt   bool
+	redact    bool
+}
+
+// structFields returns JSON fields of given struct type. Untagged embedded structs are flattened.
+// Name conflicts are not resolved unlike encoding/json.
+func structFields(t reflect.Type) []fieldInfo {
+	var ret []fieldInfo
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			for _, ef := range structFields(ft) {
+				ef.index = append([]int{i}, ef.index...)
+				ret = append(ret, ef)
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		ret = append(ret, fieldInfo{
+			index:     []int{i},
+			name:      name,
+			omitEmpty: strings.Contains(","+opts+",", ",omitempty,"),
+			encrypt:   f.Tag.Get(EncryptTag) == "true",
+			redact:    f.Tag.Get(RedactTag) == "true",
+		})
+	}
+	return ret
+}
+
+var encryptedTypes sync.Map // reflect.Type => bool
+
+var marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// hasEncrypted returns whether given type has a field to be encrypted or not.
+func hasEncrypted(t reflect.Type) bool {
+	return hasTagged(t, &encryptedTypes, func(f fieldInfo) bool { return f.encrypt })
+}
+
+// hasTagged returns whether given type has a field that tagged returns true for, caching the result in given map.
+// Interfaces may hold any type, so they are always reported to be walked with their dynamic types.
+func hasTagged(t reflect.Type, cache *sync.Map, tagged func(fieldInfo) bool) bool {
+	if v, ok := cache.Load(t); ok {
+		return v.(bool)
+	}
+	seen := map[reflect.Type]bool{}
+	ret := walkTagged(t, cache, tagged, seen)
+	if !ret {
+		// nothing reachable from t is tagged, so every type seen is final.
+		for s := range seen {
+			cache.Store(s, false)
+		}
+	}
+	return ret
+}
+
+// walkTagged is the depth first search of hasTagged. A type seen in this search counts as untagged while it is being
+// walked, so false for a type in a cycle is not final and only true is cached here.
+func walkTagged(t reflect.Type, cache *sync.Map, tagged func(fieldInfo) bool, seen map[reflect.Type]bool) bool {
+	if v, ok := cache.Load(t); ok {
+		return v.(bool)
+	}
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	ret := false
+	switch t.Kind() {
+	case reflect.Interface:
+		ret = true
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		ret = walkTagged(t.Elem(), cache, tagged, seen)
+	case reflect.Struct:
+		if t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType) {
+			break // respect custom marshaler.
+		}
+		for _, f := range structFields(t) {
+			if tagged(f) || walkTagged(t.FieldByIndex(f.index).Type, cache, tagged, seen) {
+				ret = true
+				break
+			}
+		}
+	}
+	if ret {
+		cache.Store(t, true)
+	}
+	return ret
+}
+
+type (
+	// orderedObject is a JSON object that keeps the order of fields.
+	orderedObject []objectField
+
+	objectField struct {
+		name  string
+		value interface{}
+	}
+)
+
+// MarshalJSON returns JSON object.
+func (o orderedObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(f.name)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		v, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// fieldByIndex returns the field of given struct value, false when it is in nil embedded pointer.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 {
+			if v.Kind() == reflect.Pointer {
+				if v.IsNil() {
+					return reflect.Value{}, false
+				}
+				v = v.Elem()
+			}
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// isEmptyValue returns whether given value is omitted by omitempty option or not.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Struct:
+		return false
+	}
+	return v.IsZero()
+}
+
+// encryptValue returns a value to be marshaled instead of given value.
+func encryptValue(v reflect.Value, c Cipher) (interface{}, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+	if !hasEncrypted(v.Type()) {
+		return v.Interface(), nil
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return encryptValue(v.Elem(), c)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil, nil
+		}
+		ret := make([]interface{}, v.Len())
+		for i := range ret {
+			e, err := encryptValue(v.Index(i), c)
+			if err != nil {
+				return nil, err
+			}
+			ret[i] = e
+		}
+		return ret, nil
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, nil
+		}
+		ret := make(map[string]interface{}, v.Len())
+		for it := v.MapRange(); it.Next(); {
+			k, err := mapKey(it.Key())
+			if err != nil {
+				return nil, err
+			}
+			e, err := encryptValue(it.Value(), c)
+			if err != nil {
+				return nil, err
+			}
+			ret[k] = e
+		}
+		return ret, nil
+	}
+
+	// struct
+	var ret orderedObject
+	for _, f := range structFields(v.Type()) {
+		fv, ok := fieldByIndex(v, f.index)
+		if !ok || (f.omitEmpty && isEmptyValue(fv)) {
+			continue
+		}
+		if !f.encrypt {
+			e, err := encryptValue(fv, c)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, objectField{name: f.name, value: e})
+			continue
+		}
+		plain, err := json.Marshal(fv.Interface())
+		if err != nil {
+			return nil, err
+		}
+		sealed, err := c.Seal(plain, []byte(f.name))
+		if err != nil {
+			return nil, fmt.Errorf("json: encrypt field %s: %w", f.name, err)
+		}
+		ret = append(ret, objectField{name: f.name, value: base64.RawURLEncoding.EncodeToString(sealed)})
+	}
+	return ret, nil
+}
+
+// decryptValue parses given JSON into given settable value.
+func decryptValue(data []byte, v reflect.Value, c Cipher) error {
+	if !hasEncrypted(v.Type()) {
+		return json.Unmarshal(data, v.Addr().Interface())
+	}
+	null := bytes.Equal(bytes.TrimSpace(data), []byte("null"))
+	switch v.Kind() {
+	case reflect.Interface:
+		// like encoding/json, decode into the pointer the interface holds; otherwise the type is unknown.
+		if !null && !v.IsNil() && v.Elem().Kind() == reflect.Pointer && !v.Elem().IsNil() {
+			return decryptValue(data, v.Elem().Elem(), c)
+		}
+		return json.Unmarshal(data, v.Addr().Interface())
+	case reflect.Pointer:
+		if null {
+			v.SetZero()
+			return nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return decryptValue(data, v.Elem(), c)
+	case reflect.Slice, reflect.Array:
+		if null {
+			v.SetZero()
+			return nil
+		}
+		var raws []json.RawMessage
+		if err := json.Unmarshal(data, &raws); err != nil {
+			return err
+		}
+		if v.Kind() == reflect.Slice {
+			v.Set(reflect.MakeSlice(v.Type(), len(raws), len(raws)))
+		}
+		for i := 0; i < len(raws) && i < v.Len(); i++ {
+			if err := decryptValue(raws[i], v.Index(i), c); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		if null {
+			v.SetZero()
+			return nil
+		}
+		var raws map[string]json.RawMessage
+		if err := json.Unmarshal(data, &raws); err != nil {
+			return err
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMapWithSize(v.Type(), len(raws)))
+		}
+		for k, raw := range raws {
+			kv, err := parseMapKey(k, v.Type().Key())
+			if err != nil {
+				return err
+			}
+			e := reflect.New(v.Type().Elem()).Elem()
+			if err := decryptValue(raw, e, c); err != nil {
+				return err
+			}
+			v.SetMapIndex(kv, e)
+		}
+		return nil
+	}
+
+	// struct
+	if null {
+		return nil
+	}
+	var raws map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raws); err != nil {
+		return err
+	}
+	for _, f := range structFields(v.Type()) {
+		raw, ok := lookupField(raws, f.name)
+		if !ok {
+			continue
+		}
+		fv := v
+		for i, x := range f.index {
+			if i > 0 && fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					fv.Set(reflect.New(fv.Type().Elem()))
+				}
+				fv = fv.Elem()
+			}
+			fv = fv.Field(x)
+		}
+		if !f.encrypt {
+			if err := decryptValue(raw, fv, c); err != nil {
+				return err
+			}
+			continue
+		}
+		var s *string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return fmt.Errorf("json: encrypted field %s is not a string", f.name)
+		}
+		if s == nil {
+			continue
+		}
+		sealed, err := base64.RawURLEncoding.DecodeString(*s)
+		if err != nil {
+			return fmt.Errorf("json: decrypt field %s: %w", f.name, err)
+		}
+		plain, err := c.Open(sealed, []byte(f.name))
+		if err != nil {
+			return fmt.Errorf("json: decrypt field %s: %w", f.name, err)
+		}
+		if err := json.Unmarshal(plain, fv.Addr().Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var (
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// mapKey returns JSON object key of given map key like encoding/json: strings, encoding.TextMarshaler and integers.
+func mapKey(k reflect.Value) (string, error) {
+	if k.Kind() == reflect.String {
+		return k.String(), nil
+	}
+	if k.Type().Implements(textMarshalerType) {
+		if k.Kind() == reflect.Pointer && k.IsNil() {
+			return "", nil
+		}
+		b, err := k.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(b), err
+	}
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	}
+	return "", fmt.Errorf("json: unsupported map key type %s", k.Type())
+}
+
+// parseMapKey returns map key of given type from JSON object key like encoding/json.
+func parseMapKey(k string, t reflect.Type) (reflect.Value, error) {
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		kv := reflect.New(t)
+		if err := kv.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(k)); err != nil {
+			return reflect.Value{}, err
+		}
+		return kv.Elem(), nil
+	}
+	kv := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.String:
+		kv.SetString(k)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(k, 10, t.Bits())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("json: invalid map key %q: %w", k, err)
+		}
+		kv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(k, 10, t.Bits())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("json: invalid map key %q: %w", k, err)
+		}
+		kv.SetUint(n)
+	default:
+		return reflect.Value{}, fmt.Errorf("json: unsupported map key type %s", t)
+	}
+	return kv, nil
+}
+
+// lookupField returns the value of given key, preferring an exact match to a case-insensitive one like
+// encoding/json.
+func lookupField(raws map[string]json.RawMessage, name string) (json.RawMessage, bool) {
+	if raw, ok := raws[name]; ok {
+		return raw, true
+	}
+	for k, raw := range raws {
+		if strings.EqualFold(k, name) {
+			return raw, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,215 @@
+package json_test
+
+import (
+	"encoding/base64"
+	stdjson "encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/marrbor/goutil/enc"
+	"github.com/marrbor/goutil/encoding/json"
+	"github.com/marrbor/goutil/geo"
+	"github.com/stretchr/testify/assert"
+)
+
+type Base struct {
+	ID    int64  `json:"id"`
+	Token string `json:"token" encrypt:"true"`
+}
+
+type Contact struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value" encrypt:"true"`
+}
+
+type Person struct {
+	Base
+	Name     string               `json:"name"`
+	Phone    string               `json:"phone,omitempty" encrypt:"true"`
+	Address  *geo.JapaneseAddress `json:"address" encrypt:"true"`
+	Contacts []Contact            `json:"contacts"`
+	Tags     map[string]Contact   `json:"tags,omitempty"`
+	Note     string               `json:"-"`
+}
+
+func testCipher(t *testing.T) json.Cipher {
+	r, err := enc.NewKeyRing()
+	assert.NoError(t, err)
+	_, err = r.Rotate("k1", enc.AES256GCM)
+	assert.NoError(t, err)
+	return r
+}
+
+func TestMarshalEncrypted(t *testing.T) {
+	c := testCipher(t)
+	p := Person{
+		Base:     Base{ID: 1, Token: "secret-token"},
+		Name:     "Taro",
+		Phone:    "+81-3-1234-5678",
+		Address:  &geo.JapaneseAddress{Pref: "東京都", City: "千代田区", Area: "千代田", Block: "1-1"},
+		Contacts: []Contact{{Kind: "mail", Value: "taro@example.com"}},
+		Tags:     map[string]Contact{"home": {Kind: "tel", Value: "03-0000-0000"}},
+		Note:     "ignored",
+	}
+	b, err := json.MarshalEncrypted(&p, c)
+	assert.NoError(t, err)
+	s := string(b)
+	assert.True(t, strings.HasPrefix(s, `{"id":1,"token":"`), s)
+	assert.Contains(t, s, `"name":"Taro"`)
+	assert.Contains(t, s, `"kind":"mail"`)
+	for _, secret := range []string{"secret-token", "+81-3-1234-5678", "千代田", "taro@example.com", "03-0000-0000", "ignored"} {
+		assert.NotContains(t, s, secret)
+	}
+
+	// encrypted fields are strings in plain JSON.
+	var raw map[string]interface{}
+	assert.NoError(t, stdjson.Unmarshal(b, &raw))
+	_, ok := raw["address"].(string)
+	assert.True(t, ok)
+
+	var got Person
+	assert.NoError(t, json.UnmarshalEncrypted(b, &got, c))
+	p.Note = ""
+	assert.EqualValues(t, p, got)
+}
+
+func TestMarshalEncrypted_Empty(t *testing.T) {
+	c := testCipher(t)
+	b, err := json.MarshalEncrypted(Person{Name: "x"}, c)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(b), "phone")
+	assert.NotContains(t, string(b), "tags")
+
+	var got Person
+	assert.NoError(t, json.UnmarshalEncrypted(b, &got, c))
+	assert.EqualValues(t, Person{Name: "x"}, got)
+
+	// type without encrypted field is marshaled as is.
+	b, err = json.MarshalEncrypted(map[string]int{"a": 1}, c)
+	assert.NoError(t, err)
+	assert.EqualValues(t, `{"a":1}`, string(b))
+}
+
+// contactKind is a map key marshaled as text.
+type contactKind struct{ kind string }
+
+func (k contactKind) MarshalText() ([]byte, error) { return []byte("kind-" + k.kind), nil }
+
+func (k *contactKind) UnmarshalText(b []byte) error {
+	k.kind = strings.TrimPrefix(string(b), "kind-")
+	return nil
+}
+
+type Holder struct {
+	Any    interface{}             `json:"any"`
+	ByID   map[int]Contact         `json:"by_id"`
+	ByKind map[contactKind]Contact `json:"by_kind"`
+}
+
+func TestMarshalEncrypted_Dynamic(t *testing.T) {
+	c := testCipher(t)
+	h := Holder{
+		Any:    Contact{Kind: "tel", Value: "090-1111-2222"},
+		ByID:   map[int]Contact{1: {Kind: "tel", Value: "090-3333-4444"}},
+		ByKind: map[contactKind]Contact{{kind: "mail"}: {Kind: "mail", Value: "a@example.com"}},
+	}
+	b, err := json.MarshalEncrypted(h, c)
+	assert.NoError(t, err)
+	s := string(b)
+	for _, secret := range []string{"090-1111-2222", "090-3333-4444", "a@example.com"} {
+		assert.NotContains(t, s, secret)
+	}
+	assert.Contains(t, s, `"by_id":{"1":{"kind":"tel","value":"`)
+	assert.Contains(t, s, `"by_kind":{"kind-mail":{"kind":"mail","value":"`)
+
+	// interface is decrypted into the pointer it holds.
+	got := Holder{Any: &Contact{}}
+	assert.NoError(t, json.UnmarshalEncrypted(b, &got, c))
+	assert.EqualValues(t, &Contact{Kind: "tel", Value: "090-1111-2222"}, got.Any)
+	assert.EqualValues(t, h.ByID, got.ByID)
+	assert.EqualValues(t, h.ByKind, got.ByKind)
+
+	// values in interface{} containers.
+	b, err = json.MarshalEncrypted(map[string]interface{}{"list": []interface{}{Contact{Kind: "tel", Value: "090-5555-6666"}}}, c)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(b), "090-5555-6666")
+
+	// map key that encoding/json does not support.
+	_, err = json.MarshalEncrypted(map[[2]int]Contact{{1, 2}: {Value: "x"}}, c)
+	assert.Error(t, err)
+	var bad struct {
+		ByID map[int]Contact `json:"by_id"`
+	}
+	assert.Error(t, json.UnmarshalEncrypted([]byte(`{"by_id":{"x":{}}}`), &bad, c))
+}
+
+type (
+	cycleA struct {
+		B *cycleB `json:"b"`
+	}
+
+	cycleB struct {
+		A *cycleA `json:"a"`
+		S string  `json:"s" encrypt:"true"`
+	}
+
+	chainNode struct {
+		Secret string     `json:"secret" encrypt:"true"`
+		Next   *chainNode `json:"next,omitempty"`
+	}
+)
+
+func TestMarshalEncrypted_Recursive(t *testing.T) {
+	c := testCipher(t)
+
+	// mutually recursive types.
+	v := cycleB{A: &cycleA{B: &cycleB{S: "inner-secret"}}, S: "outer-secret"}
+	b, err := json.MarshalEncrypted(v, c)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(b), "inner-secret")
+	assert.NotContains(t, string(b), "outer-secret")
+	var got cycleB
+	assert.NoError(t, json.UnmarshalEncrypted(b, &got, c))
+	assert.EqualValues(t, v, got)
+
+	b, err = json.MarshalEncrypted(cycleA{B: &cycleB{S: "a-secret"}}, c)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(b), "a-secret")
+	var gotA cycleA
+	assert.NoError(t, json.UnmarshalEncrypted(b, &gotA, c))
+	assert.EqualValues(t, "a-secret", gotA.B.S)
+
+	// recursive type.
+	n := chainNode{Secret: "s1", Next: &chainNode{Secret: "s2", Next: &chainNode{Secret: "s3"}}}
+	b, err = json.MarshalEncrypted(n, c)
+	assert.NoError(t, err)
+	for _, secret := range []string{`"s1"`, `"s2"`, `"s3"`} {
+		assert.NotContains(t, string(b), secret)
+	}
+	var gotN chainNode
+	assert.NoError(t, json.UnmarshalEncrypted(b, &gotN, c))
+	assert.EqualValues(t, n, gotN)
+}
+
+func TestUnmarshalEncrypted_Tampered(t *testing.T) {
+	c := testCipher(t)
+	b, err := json.MarshalEncrypted(Contact{Kind: "mail", Value: "a@example.com"}, c)
+	assert.NoError(t, err)
+
+	var raw map[string]string
+	assert.NoError(t, stdjson.Unmarshal(b, &raw))
+
+	// value moved to another field can not be decrypted.
+	moved, _ := stdjson.Marshal(map[string]string{"token": raw["value"]})
+	var base Base
+	assert.Error(t, json.UnmarshalEncrypted(moved, &base, c))
+
+	// another key.
+	var got Contact
+	assert.Error(t, json.UnmarshalEncrypted(b, &got, testCipher(t)))
+
+	broken, _ := stdjson.Marshal(map[string]string{"value": base64.RawURLEncoding.EncodeToString([]byte("x"))})
+	assert.Error(t, json.UnmarshalEncrypted(broken, &got, c))
+	assert.Error(t, json.UnmarshalEncrypted([]byte(`{"value":1}`), &got, c))
+	assert.Error(t, json.UnmarshalEncrypted(b, got, c))
+}
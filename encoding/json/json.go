@@ -2,9 +2,22 @@ package json
 
 import "encoding/json"
 
-// JSONString converts given structure to JSON string.
-func JSONString(params interface{}) (string, error) {
-	b, err := json.Marshal(params)
+// JSONString converts given structure to JSON string. It is compact by default, and options change the rendering:
+//
+//	s, err := json.JSONString(req, json.Indent("  "), json.SortKeys(), json.Redact())
+func JSONString(params interface{}, opts ...RenderOption) (string, error) {
+	if len(opts) == 0 {
+		b, err := json.Marshal(params)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	}
+	c := renderConfig{escapeHTML: true}
+	for _, o := range opts {
+		o(&c)
+	}
+	b, err := c.render(params)
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,95 @@
+package json
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// MergePatch applies RFC 7396 JSON Merge Patch to given JSON document and returns the result: members of patch
+// object replace those of the document recursively, and null members remove them. Object members of the result are
+// sorted by key.
+func MergePatch(doc, patch []byte) ([]byte, error) {
+	d, err := decodeDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+	p, err := decodeDocument(patch)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	return json.Marshal(mergePatch(d, p))
+}
+
+// MergePatchTo applies JSON Merge Patch to given pointer to a structure through its JSON form.
+func MergePatchTo(v interface{}, patch []byte) error {
+	return transform(v, func(doc []byte) ([]byte, error) {
+		return MergePatch(doc, patch)
+	})
+}
+
+// mergePatch applies given decoded merge patch to given decoded document.
+func mergePatch(doc, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	d, ok := doc.(map[string]interface{})
+	if !ok {
+		d = map[string]interface{}{}
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(d, k)
+			continue
+		}
+		d[k] = mergePatch(d[k], v)
+	}
+	return d
+}
+
+// CreateMergePatch returns JSON Merge Patch that transforms JSON document a into b. Note that merge patch can not
+// set null to a member, since null means removal.
+func CreateMergePatch(a, b []byte) ([]byte, error) {
+	da, err := decodeDocument(a)
+	if err != nil {
+		return nil, err
+	}
+	db, err := decodeDocument(b)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(createMergePatch(da, db))
+}
+
+// createMergePatch returns decoded merge patch that transforms a into b.
+func createMergePatch(a, b interface{}) interface{} {
+	x, ok1 := a.(map[string]interface{})
+	y, ok2 := b.(map[string]interface{})
+	if !ok1 || !ok2 {
+		return b
+	}
+	p := map[string]interface{}{}
+	for k := range x {
+		if _, ok := y[k]; !ok {
+			p[k] = nil
+		}
+	}
+	for k, v := range y {
+		old, ok := x[k]
+		if !ok {
+			p[k] = v
+			continue
+		}
+		if equalValues(old, v) {
+			continue
+		}
+		if _, isObj := v.(map[string]interface{}); isObj {
+			if _, wasObj := old.(map[string]interface{}); wasObj {
+				p[k] = createMergePatch(old, v)
+				continue
+			}
+		}
+		p[k] = v
+	}
+	return p
+}
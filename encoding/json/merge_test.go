@@ -0,0 +1,63 @@
+package json_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/marrbor/goutil/encoding/json"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergePatch(t *testing.T) {
+	// RFC 7396 appendix A.
+	tests := []struct{ doc, patch, want string }{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, tt := range tests {
+		got, err := json.MergePatch([]byte(tt.doc), []byte(tt.patch))
+		assert.NoError(t, err, tt.patch)
+		assert.EqualValues(t, normalize(t, tt.want), string(got), tt.patch)
+	}
+
+	_, err := json.MergePatch([]byte(`{}`), []byte(`{`))
+	assert.True(t, errors.Is(err, json.ErrInvalidPatch))
+	_, err = json.MergePatch([]byte(`{`), []byte(`{}`))
+	assert.Error(t, err)
+}
+
+func TestMergePatchTo(t *testing.T) {
+	v := patchTarget{Name: "a", Tags: []string{"x"}, Attrs: map[string]string{"k": "v"}}
+	assert.NoError(t, json.MergePatchTo(&v, []byte(`{"tags":null,"attrs":{"l":"w"}}`)))
+	assert.EqualValues(t, patchTarget{Name: "a", Attrs: map[string]string{"k": "v", "l": "w"}}, v)
+	assert.Error(t, json.MergePatchTo(&v, []byte(`{"name":1}`)))
+}
+
+func TestCreateMergePatch(t *testing.T) {
+	docs := []struct{ a, b, patch string }{
+		{`{"a":1,"b":{"c":1,"d":2},"e":[1]}`, `{"a":1,"b":{"c":2},"e":[1,2],"f":"x"}`, `{"b":{"c":2,"d":null},"e":[1,2],"f":"x"}`},
+		{`{"a":{"b":1}}`, `{"a":"x"}`, `{"a":"x"}`},
+		{`[1]`, `{"a":1}`, `{"a":1}`},
+	}
+	for _, d := range docs {
+		p, err := json.CreateMergePatch([]byte(d.a), []byte(d.b))
+		assert.NoError(t, err)
+		assert.EqualValues(t, normalize(t, d.patch), string(p))
+		got, err := json.MergePatch([]byte(d.a), p)
+		assert.NoError(t, err)
+		assert.EqualValues(t, normalize(t, d.b), string(got))
+	}
+}
@@ -0,0 +1,176 @@
+package json
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+)
+
+// ErrWriterClosed is returned when writing to closed LineWriter.
+var ErrWriterClosed = errors.New("json: write to closed writer")
+
+// LineError is an error of a line of NDJSON.
+type LineError struct {
+	Line int
+	Err  error
+}
+
+// Error returns error string.
+func (e *LineError) Error() string {
+	return fmt.Sprintf("json: line %d: %v", e.Line, e.Err)
+}
+
+// Unwrap returns the cause.
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// SkipBadLines makes ReadLines skip lines that can not be decoded instead of stopping at them. Given function is
+// called with the error of each skipped line when not nil. It has no effect on DecodeReader.
+func SkipBadLines(report func(*LineError)) DecodeOption {
+	return func(c *decodeConfig) {
+		c.skipBadLines = true
+		c.reportBadLine = report
+	}
+}
+
+// ReadLines returns an iterator of values of type T read from newline-delimited JSON (JSON Lines). Blank lines are
+// ignored. An error of a line is *LineError that has the line number, and iteration stops after it unless
+// SkipBadLines is given. MaxBytes limits the length of each line. Read errors of r always stop iteration.
+//
+//	for rec, err := range json.ReadLines[Record](f) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+func ReadLines[T any](r io.Reader, opts ...DecodeOption) iter.Seq2[T, error] {
+	var c decodeConfig
+	for _, o := range opts {
+		o(&c)
+	}
+	return func(yield func(T, error) bool) {
+		br := bufio.NewReader(r)
+		for n := 1; ; n++ {
+			line, err := readLine(br, c.maxBytes)
+			if err != nil && !errors.Is(err, ErrTooLarge) {
+				if err != io.EOF {
+					var zero T
+					yield(zero, &LineError{Line: n, Err: err})
+				}
+				return
+			}
+
+			var v T
+			if err == nil {
+				line = bytes.TrimSpace(line)
+				if len(line) == 0 {
+					continue
+				}
+				err = DecodeReader(bytes.NewReader(line), &v, opts...)
+			}
+			if err != nil {
+				le := &LineError{Line: n, Err: err}
+				if c.skipBadLines {
+					if c.reportBadLine != nil {
+						c.reportBadLine(le)
+					}
+					continue
+				}
+				var zero T
+				yield(zero, le)
+				return
+			}
+			if !yield(v, nil) {
+				return
+			}
+		}
+	}
+}
+
+// readLine returns next line without the newline. It returns ErrTooLarge after discarding the rest of the line when
+// the line is longer than limit (0 means no limit), and io.EOF when no more line.
+func readLine(br *bufio.Reader, limit int64) ([]byte, error) {
+	var line []byte
+	tooLarge := false
+	for {
+		b, err := br.ReadSlice('\n')
+		if !tooLarge {
+			line = append(line, b...)
+			if limit > 0 && int64(len(bytes.TrimRight(line, "\r\n"))) > limit {
+				tooLarge, line = true, nil
+			}
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if tooLarge {
+			return nil, ErrTooLarge
+		}
+		if err == io.EOF && len(line) > 0 {
+			return line, nil
+		}
+		return line, err
+	}
+}
+
+// LineWriter writes values of type T as newline-delimited JSON (JSON Lines). Output is buffered, so Close (or
+// Flush) has to be called; Close does not close the underlying writer. LineWriter satisfies closer.Client.
+type LineWriter[T any] struct {
+	bw     *bufio.Writer
+	enc    *json.Encoder
+	closed bool
+}
+
+// NewLineWriter returns LineWriter that writes to given writer. HTML characters are not escaped.
+func NewLineWriter[T any](w io.Writer) *LineWriter[T] {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	enc.SetEscapeHTML(false)
+	return &LineWriter[T]{bw: bw, enc: enc}
+}
+
+// Write writes given value as a line.
+func (w *LineWriter[T]) Write(v T) error {
+	if w.closed {
+		return ErrWriterClosed
+	}
+	return w.enc.Encode(v)
+}
+
+// Flush writes buffered lines to the underlying writer.
+func (w *LineWriter[T]) Flush() error {
+	return w.bw.Flush()
+}
+
+// Close flushes buffered lines. Writing after Close fails with ErrWriterClosed.
+func (w *LineWriter[T]) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return w.bw.Flush()
+}
+
+// WriteLines writes all values of given sequence to w as newline-delimited JSON and returns the number of lines.
+// When a value fails to be written, the lines before it are flushed and the flush error is joined to the LineError.
+func WriteLines[T any](w io.Writer, seq iter.Seq[T]) (int, error) {
+	lw := NewLineWriter[T](w)
+	n := 0
+	for v := range seq {
+		if err := lw.Write(v); err != nil {
+			// flush the lines written so far.
+			var ret error = &LineError{Line: n + 1, Err: err}
+			if cerr := lw.Close(); cerr != nil {
+				ret = errors.Join(ret, cerr)
+			}
+			return n, ret
+		}
+		n++
+	}
+	return n, lw.Close()
+}
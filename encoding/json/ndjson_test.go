@@ -0,0 +1,140 @@
+package json_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/marrbor/goutil/closer"
+	"github.com/marrbor/goutil/encoding/json"
+	"github.com/stretchr/testify/assert"
+)
+
+const lines = `{"id":1,"name":"a"}
+
+{"id":2,"name":"b"}` + "\r\n" + `{"id":"x"}
+not json
+{"id":5,"name":"e"}`
+
+func TestReadLines(t *testing.T) {
+	var got []item
+	var lineErr *json.LineError
+	for it, err := range json.ReadLines[item](strings.NewReader(lines)) {
+		if err != nil {
+			assert.True(t, errors.As(err, &lineErr))
+			break
+		}
+		got = append(got, it)
+	}
+	assert.EqualValues(t, []item{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}}, got)
+	assert.EqualValues(t, 4, lineErr.Line)
+	assert.True(t, strings.HasPrefix(lineErr.Error(), "json: line 4: "), lineErr.Error())
+
+	// stops after error.
+	n := 0
+	for _, err := range json.ReadLines[item](strings.NewReader(lines)) {
+		n++
+		_ = err
+	}
+	assert.EqualValues(t, 3, n)
+
+	// early break.
+	for range json.ReadLines[item](strings.NewReader(lines)) {
+		break
+	}
+}
+
+func TestReadLines_SkipBadLines(t *testing.T) {
+	var bad []int
+	var ids []int64
+	opt := json.SkipBadLines(func(e *json.LineError) { bad = append(bad, e.Line) })
+	for it, err := range json.ReadLines[item](strings.NewReader(lines), opt) {
+		assert.NoError(t, err)
+		ids = append(ids, it.ID)
+	}
+	assert.EqualValues(t, []int64{1, 2, 5}, ids)
+	assert.EqualValues(t, []int{4, 5}, bad)
+
+	// long lines are skipped with ErrTooLarge.
+	src := `{"id":1}` + "\n" + `{"id":2,"name":"` + strings.Repeat("x", 5000) + `"}` + "\n" + `{"id":3}`
+	ids, bad = nil, nil
+	opt = json.SkipBadLines(func(e *json.LineError) {
+		assert.True(t, errors.Is(e, json.ErrTooLarge))
+		bad = append(bad, e.Line)
+	})
+	for it, err := range json.ReadLines[item](strings.NewReader(src), opt, json.MaxBytes(100), json.DisallowUnknownFields()) {
+		assert.NoError(t, err)
+		ids = append(ids, it.ID)
+	}
+	assert.EqualValues(t, []int64{1, 3}, ids)
+	assert.EqualValues(t, []int{2}, bad)
+}
+
+func TestReadLines_ReadError(t *testing.T) {
+	boom := errors.New("boom")
+	r := io.MultiReader(strings.NewReader("{\"id\":1}\n{\"id\":2"), iotest.ErrReader(boom))
+	var errs []error
+	for _, err := range json.ReadLines[item](r, json.SkipBadLines(nil)) {
+		errs = append(errs, err)
+	}
+	// read error is not skipped.
+	assert.EqualValues(t, 2, len(errs))
+	assert.NoError(t, errs[0])
+	assert.True(t, errors.Is(errs[1], boom))
+}
+
+func TestLineWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := json.NewLineWriter[item](&buf)
+	var g closer.Group
+	assert.NoError(t, g.Add("ndjson", w))
+
+	assert.NoError(t, w.Write(item{ID: 1, Name: "a"}))
+	assert.NoError(t, w.Write(item{ID: 2, Name: "<b>"}))
+	assert.EqualValues(t, 0, buf.Len()) // buffered.
+
+	assert.NoError(t, g.Close())
+	assert.EqualValues(t, `{"id":1,"name":"a"}`+"\n"+`{"id":2,"name":"<b>"}`+"\n", buf.String())
+	assert.True(t, errors.Is(w.Write(item{}), json.ErrWriterClosed))
+	assert.NoError(t, w.Close())
+
+	// round trip.
+	var got []item
+	for it, err := range json.ReadLines[item](&buf) {
+		assert.NoError(t, err)
+		got = append(got, it)
+	}
+	assert.EqualValues(t, []item{{ID: 1, Name: "a"}, {ID: 2, Name: "<b>"}}, got)
+}
+
+func TestWriteLines(t *testing.T) {
+	var buf bytes.Buffer
+	n, err := json.WriteLines(&buf, slices.Values([]int{1, 2, 3}))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, n)
+	assert.EqualValues(t, "1\n2\n3\n", buf.String())
+
+	buf.Reset()
+	n, err = json.WriteLines(&buf, slices.Values([]interface{}{1, make(chan int)}))
+	var lineErr *json.LineError
+	assert.True(t, errors.As(err, &lineErr))
+	assert.EqualValues(t, 2, lineErr.Line)
+	assert.EqualValues(t, 1, n)
+	assert.EqualValues(t, "1\n", buf.String()) // lines before the error are flushed.
+
+	// flush error is joined.
+	_, err = json.WriteLines(failWriter{}, slices.Values([]interface{}{1, make(chan int)}))
+	assert.True(t, errors.As(err, &lineErr))
+	assert.True(t, errors.Is(err, errWrite))
+}
+
+var errWrite = errors.New("write failed")
+
+// failWriter fails every write.
+type failWriter struct{}
+
+func (failWriter) Write([]byte) (int, error) { return 0, errWrite }
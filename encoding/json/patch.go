@@ -0,0 +1,348 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+var (
+	ErrInvalidPatch = errors.New("json: invalid patch")
+	ErrTestFailed   = errors.New("json: test failed")
+)
+
+// JSON Patch operations.
+const (
+	OpAdd     = "add"
+	OpRemove  = "remove"
+	OpReplace = "replace"
+	OpMove    = "move"
+	OpCopy    = "copy"
+	OpTest    = "test"
+)
+
+type (
+	// Operation is an operation of RFC 6902 JSON Patch. Value is nil when the member is absent, and "null" for JSON
+	// null.
+	Operation struct {
+		Op    string          `json:"op"`
+		Path  string          `json:"path"`
+		From  string          `json:"from,omitempty"`
+		Value json.RawMessage `json:"value,omitempty"`
+	}
+
+	// Patch is RFC 6902 JSON Patch document.
+	Patch []Operation
+
+	// PatchError is an error of a JSON Patch operation.
+	PatchError struct {
+		Index int    // index of the failed operation.
+		Op    string // name of the failed operation.
+		Path  string // JSON Pointer of the operation target.
+		Err   error
+	}
+)
+
+// Error returns error string.
+func (e *PatchError) Error() string {
+	return fmt.Sprintf("json: patch operation %d (%s %q): %v", e.Index, e.Op, e.Path, e.Err)
+}
+
+// Unwrap returns the cause.
+func (e *PatchError) Unwrap() error {
+	return e.Err
+}
+
+// DecodePatch parses JSON Patch document.
+func DecodePatch(data []byte) (Patch, error) {
+	var p Patch
+	if err := DecodeReader(bytes.NewReader(data), &p); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	return p, nil
+}
+
+// ApplyPatch applies given JSON Patch document to given JSON document and returns the result.
+func ApplyPatch(doc, patch []byte) ([]byte, error) {
+	p, err := DecodePatch(patch)
+	if err != nil {
+		return nil, err
+	}
+	return p.Apply(doc)
+}
+
+// decodeDocument parses given JSON document keeping numbers as json.Number.
+func decodeDocument(data []byte) (interface{}, error) {
+	var doc interface{}
+	if err := DecodeReader(bytes.NewReader(data), &doc, UseNumber()); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// Apply applies the patch to given JSON document and returns the result. Operations are applied in order and the
+// whole patch fails with *PatchError when one of them fails. Object members of the result are sorted by key.
+func (p Patch) Apply(doc []byte) ([]byte, error) {
+	d, err := decodeDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+	if d, err = p.apply(d); err != nil {
+		return nil, err
+	}
+	return json.Marshal(d)
+}
+
+// ApplyTo applies the patch to given pointer to a structure through its JSON form.
+func (p Patch) ApplyTo(v interface{}) error {
+	return transform(v, p.Apply)
+}
+
+// transform replaces the value of given pointer with the result of f applied to its JSON form.
+func transform(v interface{}, f func([]byte) ([]byte, error)) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("json: needs non-nil pointer, got %T", v)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if b, err = f(b); err != nil {
+		return err
+	}
+	// removed members have to be cleared.
+	nv := reflect.New(rv.Type().Elem())
+	if err := json.Unmarshal(b, nv.Interface()); err != nil {
+		return err
+	}
+	rv.Elem().Set(nv.Elem())
+	return nil
+}
+
+// apply applies the patch to given decoded document.
+func (p Patch) apply(doc interface{}) (interface{}, error) {
+	for i, op := range p {
+		var err error
+		if doc, err = op.apply(doc); err != nil {
+			return nil, &PatchError{Index: i, Op: op.Op, Path: op.Path, Err: err}
+		}
+	}
+	return doc, nil
+}
+
+// value returns decoded value of the operation.
+func (o *Operation) value() (interface{}, error) {
+	if o.Value == nil {
+		return nil, fmt.Errorf("%w: missing value", ErrInvalidPatch)
+	}
+	return decodeDocument(o.Value)
+}
+
+// apply applies the operation to given decoded document.
+func (o *Operation) apply(doc interface{}) (interface{}, error) {
+	path, err := parsePointer(o.Path)
+	if err != nil {
+		return nil, err
+	}
+	switch o.Op {
+	case OpAdd:
+		v, err := o.value()
+		if err != nil {
+			return nil, err
+		}
+		return addValue(doc, path, v)
+	case OpRemove:
+		doc, _, err = removeValue(doc, path)
+		return doc, err
+	case OpReplace:
+		v, err := o.value()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := lookup(doc, path); err != nil {
+			return nil, err
+		}
+		if len(path) == 0 {
+			return v, nil
+		}
+		doc, _, _ = removeValue(doc, path)
+		return addValue(doc, path, v)
+	case OpMove, OpCopy:
+		from, err := parsePointer(o.From)
+		if err != nil {
+			return nil, err
+		}
+		if o.Op == OpMove && o.From == o.Path {
+			_, err := lookup(doc, from)
+			return doc, err
+		}
+		if o.Op == OpMove && isProperPrefix(from, path) {
+			return nil, fmt.Errorf("%w: can not move %q into its child", ErrInvalidPatch, o.From)
+		}
+		var v interface{}
+		if o.Op == OpMove {
+			doc, v, err = removeValue(doc, from)
+		} else {
+			v, err = lookup(doc, from)
+			v = deepCopy(v)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("from: %w", err)
+		}
+		return addValue(doc, path, v)
+	case OpTest:
+		v, err := o.value()
+		if err != nil {
+			return nil, err
+		}
+		cur, err := lookup(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !equalValues(cur, v) {
+			return nil, fmt.Errorf("%w: value is %s", ErrTestFailed, compactJSON(cur))
+		}
+		return doc, nil
+	}
+	return nil, fmt.Errorf("%w: unknown op %q", ErrInvalidPatch, o.Op)
+}
+
+// isProperPrefix returns whether given tokens a are a proper prefix of b or not.
+func isProperPrefix(a, b []string) bool {
+	if len(a) >= len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// compactJSON returns JSON string of given decoded value for error messages.
+func compactJSON(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	if len(b) > 64 {
+		return string(b[:61]) + "..."
+	}
+	return string(b)
+}
+
+// Diff returns JSON Patch that transforms JSON document a into b. The patch consists of add, remove and replace
+// operations.
+func Diff(a, b []byte) (Patch, error) {
+	da, err := decodeDocument(a)
+	if err != nil {
+		return nil, err
+	}
+	db, err := decodeDocument(b)
+	if err != nil {
+		return nil, err
+	}
+	return diff(nil, nil, da, db)
+}
+
+// DiffValues returns JSON Patch that transforms JSON form of a into that of b.
+func DiffValues(a, b interface{}) (Patch, error) {
+	ja, err := json.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+	jb, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+	return Diff(ja, jb)
+}
+
+// newOperation returns an operation that has given value.
+func newOperation(op string, path []string, v interface{}) (Operation, error) {
+	o := Operation{Op: op, Path: formatPointer(path)}
+	if op == OpRemove {
+		return o, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return o, err
+	}
+	o.Value = b
+	return o, nil
+}
+
+// diff appends operations that transform a into b at given path to given patch.
+func diff(p Patch, path []string, a, b interface{}) (Patch, error) {
+	at := func(token string) []string {
+		return append(path[:len(path):len(path)], token)
+	}
+	add := func(op string, path []string, v interface{}) error {
+		o, err := newOperation(op, path, v)
+		if err == nil {
+			p = append(p, o)
+		}
+		return err
+	}
+
+	switch x := a.(type) {
+	case map[string]interface{}:
+		y, ok := b.(map[string]interface{})
+		if !ok {
+			break
+		}
+		var err error
+		for _, k := range sortedKeys(x) {
+			if _, ok := y[k]; !ok {
+				if err := add(OpRemove, at(k), nil); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if p, err = diff(p, at(k), x[k], y[k]); err != nil {
+				return nil, err
+			}
+		}
+		for _, k := range sortedKeys(y) {
+			if _, ok := x[k]; !ok {
+				if err := add(OpAdd, at(k), y[k]); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return p, nil
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok {
+			break
+		}
+		var err error
+		for i := 0; i < len(x) && i < len(y); i++ {
+			if p, err = diff(p, at(strconv.Itoa(i)), x[i], y[i]); err != nil {
+				return nil, err
+			}
+		}
+		for i := len(x) - 1; i >= len(y); i-- {
+			if err := add(OpRemove, at(strconv.Itoa(i)), nil); err != nil {
+				return nil, err
+			}
+		}
+		for i := len(x); i < len(y); i++ {
+			if err := add(OpAdd, at(strconv.Itoa(i)), y[i]); err != nil {
+				return nil, err
+			}
+		}
+		return p, nil
+	}
+	if !equalValues(a, b) {
+		if err := add(OpReplace, path, b); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
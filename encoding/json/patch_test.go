@@ -0,0 +1,151 @@
+package json_test
+
+import (
+	stdjson "encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/marrbor/goutil/encoding/json"
+	"github.com/stretchr/testify/assert"
+)
+
+// normalize returns given JSON with sorted keys and no spaces.
+func normalize(t *testing.T, s string) string {
+	t.Helper()
+	var v interface{}
+	d := stdjson.NewDecoder(strings.NewReader(s))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		t.Fatal(err)
+	}
+	b, err := stdjson.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestApplyPatch(t *testing.T) {
+	// RFC 6902 appendix A.
+	tests := []struct {
+		doc, patch, want string
+	}{
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`},
+		{`{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{`{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{`{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{`{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{`{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`, `{"baz":"qux","foo":["a",2,"c"]}`},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`, `{"foo":"bar","child":{"grandchild":{}}}`},
+		{`{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`, `{"foo":["bar",["abc","def"]]}`},
+		{`{"foo":null}`, `[{"op":"test","path":"/foo","value":null}]`, `{"foo":null}`},
+		{`{"/":9,"~1":10}`, `[{"op":"test","path":"/~01","value":10}]`, `{"/":9,"~1":10}`},
+		{`{"foo":1}`, `[{"op":"add","path":"","value":[1]}]`, `[1]`},
+		{`{"foo":{"a":[1]}}`, `[{"op":"copy","from":"/foo","path":"/bar"},{"op":"add","path":"/bar/a/-","value":2}]`, `{"foo":{"a":[1]},"bar":{"a":[1,2]}}`},
+		{`{"n":1.0}`, `[{"op":"test","path":"/n","value":1},{"op":"replace","path":"/n","value":12345678901234567890}]`, `{"n":12345678901234567890}`},
+		{`[1,2]`, `[{"op":"move","from":"/0","path":"/0"}]`, `[1,2]`},
+	}
+	for _, tt := range tests {
+		got, err := json.ApplyPatch([]byte(tt.doc), []byte(tt.patch))
+		assert.NoError(t, err, tt.patch)
+		assert.EqualValues(t, normalize(t, tt.want), string(got), tt.patch)
+	}
+}
+
+func TestApplyPatch_Error(t *testing.T) {
+	tests := []struct {
+		doc, patch string
+		index      int
+		path       string
+		err        error
+	}{
+		{`{"baz":"qux"}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/baz","value":"bar"}]`, 1, "/baz", json.ErrTestFailed},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`, 0, "/baz/bat", json.ErrNotFound},
+		{`{"foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, 0, "/baz", json.ErrNotFound},
+		{`{"foo":"bar"}`, `[{"op":"replace","path":"/baz","value":1}]`, 0, "/baz", json.ErrNotFound},
+		{`{"foo":[1]}`, `[{"op":"add","path":"/foo/2","value":1}]`, 0, "/foo/2", json.ErrNotFound},
+		{`{"foo":[1]}`, `[{"op":"add","path":"/foo/01","value":1}]`, 0, "/foo/01", json.ErrInvalidPointer},
+		{`{"foo":1}`, `[{"op":"add","path":"foo","value":1}]`, 0, "foo", json.ErrInvalidPointer},
+		{`{"foo":1}`, `[{"op":"add","path":"/~2","value":1}]`, 0, "/~2", json.ErrInvalidPointer},
+		{`{"foo":1}`, `[{"op":"add","path":"/bar"}]`, 0, "/bar", json.ErrInvalidPatch},
+		{`{"foo":1}`, `[{"op":"inc","path":"/foo"}]`, 0, "/foo", json.ErrInvalidPatch},
+		{`{"foo":{"a":1}}`, `[{"op":"move","from":"/foo","path":"/foo/a/b"}]`, 0, "/foo/a/b", json.ErrInvalidPatch},
+		{`{"foo":1}`, `[{"op":"copy","from":"/bar","path":"/baz"}]`, 0, "/baz", json.ErrNotFound},
+	}
+	for _, tt := range tests {
+		_, err := json.ApplyPatch([]byte(tt.doc), []byte(tt.patch))
+		var pe *json.PatchError
+		if assert.True(t, errors.As(err, &pe), tt.patch) {
+			assert.EqualValues(t, tt.index, pe.Index, tt.patch)
+			assert.EqualValues(t, tt.path, pe.Path, tt.patch)
+			assert.True(t, errors.Is(err, tt.err), "%s: %v", tt.patch, err)
+		}
+	}
+	assert.EqualValues(t, `json: patch operation 1 (test "/baz"): json: test failed: value is "qux"`,
+		func() string {
+			_, err := json.ApplyPatch([]byte(tests[0].doc), []byte(tests[0].patch))
+			return err.Error()
+		}())
+
+	_, err := json.ApplyPatch([]byte(`{}`), []byte(`{"op":"add"}`))
+	assert.True(t, errors.Is(err, json.ErrInvalidPatch))
+	_, err = json.ApplyPatch([]byte(`{`), []byte(`[]`))
+	assert.Error(t, err)
+}
+
+type patchTarget struct {
+	Name  string            `json:"name"`
+	Tags  []string          `json:"tags,omitempty"`
+	Attrs map[string]string `json:"attrs,omitempty"`
+}
+
+func TestPatch_ApplyTo(t *testing.T) {
+	v := patchTarget{Name: "a", Tags: []string{"x"}, Attrs: map[string]string{"k": "v", "l": "w"}}
+	p, err := json.DecodePatch([]byte(`[{"op":"replace","path":"/name","value":"b"},{"op":"add","path":"/tags/0","value":"w"},{"op":"remove","path":"/attrs/k"}]`))
+	assert.NoError(t, err)
+	assert.NoError(t, p.ApplyTo(&v))
+	assert.EqualValues(t, patchTarget{Name: "b", Tags: []string{"w", "x"}, Attrs: map[string]string{"l": "w"}}, v)
+
+	// failed patch does not change the value.
+	p = json.Patch{{Op: json.OpRemove, Path: "/missing"}}
+	assert.Error(t, p.ApplyTo(&v))
+	assert.EqualValues(t, "b", v.Name)
+	assert.Error(t, p.ApplyTo(v))
+}
+
+func TestDiff(t *testing.T) {
+	docs := []struct{ a, b string }{
+		{`{"a":1,"b":[1,2,3],"c":{"d":"e"}}`, `{"a":2,"b":[1,3],"c":{"d":"e","f":null},"g":true}`},
+		{`[1,2]`, `[1,2,3,4]`},
+		{`{"a":{"b":1}}`, `{"a":[1]}`},
+		{`{"a/b":1,"c~d":2}`, `{"a/b":3}`},
+		{`1`, `"x"`},
+		{`{"n":1.0}`, `{"n":1}`},
+	}
+	for _, d := range docs {
+		p, err := json.Diff([]byte(d.a), []byte(d.b))
+		assert.NoError(t, err)
+		got, err := p.Apply([]byte(d.a))
+		assert.NoError(t, err)
+		if d.a == `{"n":1.0}` {
+			assert.EqualValues(t, 0, len(p))
+			continue
+		}
+		assert.EqualValues(t, normalize(t, d.b), string(got), d.a)
+	}
+
+	p, err := json.Diff([]byte(`{"a":1,"b":2,"c":[1,2,3]}`), []byte(`{"a":1,"c":[1],"d":"x"}`))
+	assert.NoError(t, err)
+	b, err := stdjson.Marshal(p)
+	assert.NoError(t, err)
+	assert.EqualValues(t, `[{"op":"remove","path":"/b"},{"op":"remove","path":"/c/2"},{"op":"remove","path":"/c/1"},{"op":"add","path":"/d","value":"x"}]`, string(b))
+
+	p, err = json.DiffValues(patchTarget{Name: "a"}, patchTarget{Name: "b", Tags: []string{"t"}})
+	assert.NoError(t, err)
+	b, err = stdjson.Marshal(p)
+	assert.NoError(t, err)
+	assert.EqualValues(t, `[{"op":"replace","path":"/name","value":"b"},{"op":"add","path":"/tags","value":["t"]}]`, string(b))
+}
@@ -0,0 +1,434 @@
+package json
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidPointer = errors.New("json: invalid pointer")
+	ErrNotFound       = errors.New("json: value not found")
+)
+
+// pointerEscaper escapes a reference token of JSON Pointer.
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// parsePointer returns reference tokens of given RFC 6901 JSON Pointer. The pointer "" refers to the whole document.
+func parsePointer(p string) ([]string, error) {
+	if p == "" {
+		return nil, nil
+	}
+	if p[0] != '/' {
+		return nil, fmt.Errorf("%w: %q does not start with '/'", ErrInvalidPointer, p)
+	}
+	tokens := strings.Split(p[1:], "/")
+	for i, t := range tokens {
+		if !strings.Contains(t, "~") {
+			continue
+		}
+		for j := 0; j < len(t); j++ {
+			if t[j] == '~' && (j+1 == len(t) || (t[j+1] != '0' && t[j+1] != '1')) {
+				return nil, fmt.Errorf("%w: bad escape in %q", ErrInvalidPointer, p)
+			}
+		}
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// formatPointer returns JSON Pointer of given reference tokens.
+func formatPointer(tokens []string) string {
+	var sb strings.Builder
+	for _, t := range tokens {
+		sb.WriteByte('/')
+		sb.WriteString(pointerEscaper.Replace(t))
+	}
+	return sb.String()
+}
+
+// arrayIndex returns array index of given reference token. "-" is the index after the last element.
+// Leading zeros are not allowed.
+func arrayIndex(token string, length int) (int, error) {
+	if token == "-" {
+		return length, nil
+	}
+	if token == "" || (len(token) > 1 && token[0] == '0') || strings.TrimLeft(token, "0123456789") != "" {
+		return 0, fmt.Errorf("%w: bad array index %q", ErrInvalidPointer, token)
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil {
+		return 0, fmt.Errorf("%w: bad array index %q", ErrInvalidPointer, token)
+	}
+	return i, nil
+}
+
+// child returns the member of given container that has given reference token.
+func child(doc interface{}, token string) (interface{}, error) {
+	switch d := doc.(type) {
+	case map[string]interface{}:
+		v, ok := d[token]
+		if !ok {
+			return nil, fmt.Errorf("%w: member %q", ErrNotFound, token)
+		}
+		return v, nil
+	case []interface{}:
+		i, err := arrayIndex(token, len(d))
+		if err != nil {
+			return nil, err
+		}
+		if i >= len(d) {
+			return nil, fmt.Errorf("%w: index %s out of range", ErrNotFound, token)
+		}
+		return d[i], nil
+	}
+	return nil, fmt.Errorf("%w: %q of %s", ErrNotFound, token, typeName(doc))
+}
+
+// lookup returns the value that given tokens refer to.
+func lookup(doc interface{}, tokens []string) (interface{}, error) {
+	for _, t := range tokens {
+		var err error
+		if doc, err = child(doc, t); err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+// modify returns the document whose container that has the last token is replaced with the result of f.
+// f receives the container (the parent of the target) and the last token.
+func modify(doc interface{}, tokens []string, f func(parent interface{}, token string) (interface{}, error)) (interface{}, error) {
+	if len(tokens) == 1 {
+		return f(doc, tokens[0])
+	}
+	c, err := child(doc, tokens[0])
+	if err != nil {
+		return nil, err
+	}
+	nc, err := modify(c, tokens[1:], f)
+	if err != nil {
+		return nil, err
+	}
+	switch d := doc.(type) {
+	case map[string]interface{}:
+		d[tokens[0]] = nc
+	case []interface{}:
+		i, _ := arrayIndex(tokens[0], len(d))
+		d[i] = nc
+	}
+	return doc, nil
+}
+
+// addValue returns the document with given value added at given tokens as JSON Patch "add" operation: a member is
+// created or replaced, and a value is inserted into an array. Empty tokens replace the whole document.
+func addValue(doc interface{}, tokens []string, value interface{}) (interface{}, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	return modify(doc, tokens, func(parent interface{}, token string) (interface{}, error) {
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			p[token] = value
+			return p, nil
+		case []interface{}:
+			i, err := arrayIndex(token, len(p))
+			if err != nil {
+				return nil, err
+			}
+			if i > len(p) {
+				return nil, fmt.Errorf("%w: index %s out of range", ErrNotFound, token)
+			}
+			p = append(p, nil)
+			copy(p[i+1:], p[i:])
+			p[i] = value
+			return p, nil
+		}
+		return nil, fmt.Errorf("%w: parent of %q is %s", ErrNotFound, token, typeName(parent))
+	})
+}
+
+// removeValue returns the document with the value at given tokens removed, and the removed value.
+func removeValue(doc interface{}, tokens []string) (interface{}, interface{}, error) {
+	if len(tokens) == 0 {
+		return nil, doc, nil
+	}
+	var removed interface{}
+	doc, err := modify(doc, tokens, func(parent interface{}, token string) (interface{}, error) {
+		v, err := child(parent, token)
+		if err != nil {
+			return nil, err
+		}
+		removed = v
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			delete(p, token)
+			return p, nil
+		case []interface{}:
+			i, _ := arrayIndex(token, len(p))
+			return append(p[:i], p[i+1:]...), nil
+		}
+		return parent, nil
+	})
+	return doc, removed, err
+}
+
+// typeName returns JSON type name of given value.
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number, float64, int, int64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// deepCopy returns a copy of given document that shares nothing with it.
+func deepCopy(v interface{}) interface{} {
+	switch d := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(d))
+		for k, e := range d {
+			m[k] = deepCopy(e)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(d))
+		for i, e := range d {
+			l[i] = deepCopy(e)
+		}
+		return l
+	}
+	return v
+}
+
+// equalValues returns whether given documents are equal. Numbers are compared by their values.
+func equalValues(a, b interface{}) bool {
+	switch x := a.(type) {
+	case map[string]interface{}:
+		y, ok := b.(map[string]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, e := range x {
+			f, ok := y[k]
+			if !ok || !equalValues(e, f) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !equalValues(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	case json.Number, float64, int, int64:
+		return compareNumbers(a, b) == 0
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// compareNumbers compares given numbers, returns -2 when either is not a number.
+func compareNumbers(a, b interface{}) int {
+	x, ok1 := toRat(a)
+	y, ok2 := toRat(b)
+	if !ok1 || !ok2 {
+		return -2
+	}
+	return x.Cmp(y)
+}
+
+// toRat returns exact value of given number.
+func toRat(v interface{}) (*big.Rat, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		return new(big.Rat).SetString(string(n))
+	case float64:
+		r := new(big.Rat)
+		if r.SetFloat64(n) == nil {
+			return nil, false
+		}
+		return r, true
+	case int:
+		return new(big.Rat).SetInt64(int64(n)), true
+	case int64:
+		return new(big.Rat).SetInt64(n), true
+	}
+	return nil, false
+}
+
+// sortedKeys returns sorted keys of given object.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Pointer returns RFC 6901 JSON Pointer of given reference tokens, escaping '~' and '/' in them.
+func Pointer(tokens ...string) string {
+	return formatPointer(tokens)
+}
+
+// GetPointer returns raw JSON of the value that given JSON Pointer refers to in given JSON document.
+func GetPointer(doc []byte, pointer string) (json.RawMessage, error) {
+	d, err := decodeDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+	v, err := Lookup(d, pointer)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// GetPointerAs returns the value that given JSON Pointer refers to in given JSON document as type T.
+func GetPointerAs[T any](doc []byte, pointer string) (T, error) {
+	var v T
+	raw, err := GetPointer(doc, pointer)
+	if err != nil {
+		return v, err
+	}
+	err = json.Unmarshal(raw, &v)
+	return v, err
+}
+
+// SetPointer returns given JSON document whose value at given JSON Pointer is set to given value. An object member
+// is created or replaced, an array element is replaced, and "-" appends to an array. The parent has to exist.
+func SetPointer(doc []byte, pointer string, value interface{}) ([]byte, error) {
+	return editDocument(doc, func(d interface{}) (interface{}, error) {
+		return setValue(d, pointer, value)
+	})
+}
+
+// DeletePointer returns given JSON document without the value at given JSON Pointer.
+func DeletePointer(doc []byte, pointer string) ([]byte, error) {
+	return editDocument(doc, func(d interface{}) (interface{}, error) {
+		tokens, err := parsePointer(pointer)
+		if err != nil {
+			return nil, err
+		}
+		d, _, err = removeValue(d, tokens)
+		return d, err
+	})
+}
+
+// editDocument returns given JSON document modified by f. Object members of the result are sorted by key.
+func editDocument(doc []byte, f func(interface{}) (interface{}, error)) ([]byte, error) {
+	d, err := decodeDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+	if d, err = f(d); err != nil {
+		return nil, err
+	}
+	return json.Marshal(d)
+}
+
+// Lookup returns the value that given JSON Pointer refers to in given decoded document such as
+// map[string]interface{} and []interface{}.
+func Lookup(doc interface{}, pointer string) (interface{}, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	v, err := lookup(doc, tokens)
+	if err != nil {
+		return nil, fmt.Errorf("%w at %q", err, pointer)
+	}
+	return v, nil
+}
+
+// LookupAs returns the value that given JSON Pointer refers to in given decoded document as type T. The value is
+// converted through JSON when it is not T.
+func LookupAs[T any](doc interface{}, pointer string) (T, error) {
+	v, err := Lookup(doc, pointer)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return convertTo[T](v)
+}
+
+// Set sets given value at given JSON Pointer in given object in place. See SetPointer.
+func Set(doc map[string]interface{}, pointer string, value interface{}) error {
+	if pointer == "" {
+		return fmt.Errorf("%w: can not replace the whole document", ErrInvalidPointer)
+	}
+	_, err := setValue(doc, pointer, value)
+	return err
+}
+
+// Delete deletes the value at given JSON Pointer from given object in place.
+func Delete(doc map[string]interface{}, pointer string) error {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		return fmt.Errorf("%w: can not delete the whole document", ErrInvalidPointer)
+	}
+	_, _, err = removeValue(doc, tokens)
+	return err
+}
+
+// setValue returns the document whose value at given pointer is set to given value.
+func setValue(doc interface{}, pointer string, value interface{}) (interface{}, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	return modify(doc, tokens, func(parent interface{}, token string) (interface{}, error) {
+		if l, ok := parent.([]interface{}); ok {
+			i, err := arrayIndex(token, len(l))
+			if err != nil {
+				return nil, err
+			}
+			if i < len(l) {
+				l[i] = value
+				return l, nil
+			}
+		}
+		return addValue(parent, []string{token}, value)
+	})
+}
+
+// convertTo returns given decoded value as type T, converting it through JSON when it is not T.
+func convertTo[T any](v interface{}) (T, error) {
+	if t, ok := v.(T); ok {
+		return t, nil
+	}
+	var t T
+	b, err := json.Marshal(v)
+	if err != nil {
+		return t, err
+	}
+	err = json.Unmarshal(b, &t)
+	return t, err
+}
@@ -0,0 +1,177 @@
+package json_test
+
+import (
+	stdjson "encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/marrbor/goutil/encoding/json"
+	"github.com/stretchr/testify/assert"
+)
+
+// rfc6901 is the example document of RFC 6901 section 5.
+const rfc6901 = `{"foo":["bar","baz"],"":0,"a/b":1,"c%d":2,"e^f":3,"g|h":4,"i\\j":5,"k\"l":6," ":7,"m~n":8}`
+
+func TestGetPointer(t *testing.T) {
+	tests := []struct {
+		pointer, want string
+	}{
+		{"/foo", `["bar","baz"]`},
+		{"/foo/0", `"bar"`},
+		{"/", `0`},
+		{"/a~1b", `1`},
+		{"/c%d", `2`},
+		{"/e^f", `3`},
+		{"/g|h", `4`},
+		{"/i\\j", `5`},
+		{"/k\"l", `6`},
+		{"/ ", `7`},
+		{"/m~0n", `8`},
+	}
+	for _, tt := range tests {
+		raw, err := json.GetPointer([]byte(rfc6901), tt.pointer)
+		assert.NoError(t, err, tt.pointer)
+		assert.EqualValues(t, tt.want, string(raw), tt.pointer)
+	}
+
+	raw, err := json.GetPointer([]byte(rfc6901), "")
+	assert.NoError(t, err)
+	assert.EqualValues(t, normalize(t, rfc6901), string(raw))
+
+	_, err = json.GetPointer([]byte(rfc6901), "/foo/2")
+	assert.True(t, errors.Is(err, json.ErrNotFound))
+	_, err = json.GetPointer([]byte(rfc6901), "/nothing")
+	assert.True(t, errors.Is(err, json.ErrNotFound))
+	_, err = json.GetPointer([]byte(rfc6901), "foo")
+	assert.True(t, errors.Is(err, json.ErrInvalidPointer))
+	_, err = json.GetPointer([]byte(`{`), "/foo")
+	assert.Error(t, err)
+}
+
+func TestGetPointerAs(t *testing.T) {
+	doc := []byte(`{"user":{"id":123,"name":"alice","tags":["a","b"],"item":{"id":1,"name":"one"}}}`)
+
+	id, err := json.GetPointerAs[int64](doc, "/user/id")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 123, id)
+
+	name, err := json.GetPointerAs[string](doc, "/user/name")
+	assert.NoError(t, err)
+	assert.EqualValues(t, "alice", name)
+
+	tags, err := json.GetPointerAs[[]string](doc, "/user/tags")
+	assert.NoError(t, err)
+	assert.EqualValues(t, []string{"a", "b"}, tags)
+
+	it, err := json.GetPointerAs[item](doc, "/user/item")
+	assert.NoError(t, err)
+	assert.EqualValues(t, item{ID: 1, Name: "one"}, it)
+
+	_, err = json.GetPointerAs[int](doc, "/user/name")
+	assert.Error(t, err)
+	_, err = json.GetPointerAs[int](doc, "/user/age")
+	assert.True(t, errors.Is(err, json.ErrNotFound))
+}
+
+func TestSetPointer(t *testing.T) {
+	doc := []byte(`{"a":{"b":1},"l":[1,2]}`)
+	tests := []struct {
+		pointer string
+		value   interface{}
+		want    string
+	}{
+		{"/a/b", 2, `{"a":{"b":2},"l":[1,2]}`},
+		{"/a/c", "x", `{"a":{"b":1,"c":"x"},"l":[1,2]}`},
+		{"/l/0", true, `{"a":{"b":1},"l":[true,2]}`},
+		{"/l/-", 3, `{"a":{"b":1},"l":[1,2,3]}`},
+		{"/a", []int{1}, `{"a":[1],"l":[1,2]}`},
+		{"", map[string]int{"z": 0}, `{"z":0}`},
+	}
+	for _, tt := range tests {
+		b, err := json.SetPointer(doc, tt.pointer, tt.value)
+		assert.NoError(t, err, tt.pointer)
+		assert.EqualValues(t, tt.want, string(b), tt.pointer)
+	}
+
+	_, err := json.SetPointer(doc, "/x/y", 1)
+	assert.True(t, errors.Is(err, json.ErrNotFound))
+	_, err = json.SetPointer(doc, "/l/3", 1)
+	assert.True(t, errors.Is(err, json.ErrNotFound))
+}
+
+func TestDeletePointer(t *testing.T) {
+	doc := []byte(`{"a":{"b":1,"c":2},"l":[1,2,3]}`)
+
+	b, err := json.DeletePointer(doc, "/a/b")
+	assert.NoError(t, err)
+	assert.EqualValues(t, `{"a":{"c":2},"l":[1,2,3]}`, string(b))
+
+	b, err = json.DeletePointer(doc, "/l/1")
+	assert.NoError(t, err)
+	assert.EqualValues(t, `{"a":{"b":1,"c":2},"l":[1,3]}`, string(b))
+
+	_, err = json.DeletePointer(doc, "/a/x")
+	assert.True(t, errors.Is(err, json.ErrNotFound))
+}
+
+func TestLookup(t *testing.T) {
+	var doc map[string]interface{}
+	assert.NoError(t, stdjson.Unmarshal([]byte(rfc6901), &doc))
+
+	v, err := json.Lookup(doc, "/foo/1")
+	assert.NoError(t, err)
+	assert.EqualValues(t, "baz", v)
+
+	n, err := json.LookupAs[float64](doc, "/m~0n")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 8, n)
+
+	// converted through JSON.
+	i, err := json.LookupAs[int](doc, "/a~1b")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, i)
+	l, err := json.LookupAs[[]string](doc, "/foo")
+	assert.NoError(t, err)
+	assert.EqualValues(t, []string{"bar", "baz"}, l)
+
+	_, err = json.Lookup(doc, "/foo/-")
+	assert.True(t, errors.Is(err, json.ErrNotFound))
+	_, err = json.Lookup(doc, "/foo/01")
+	assert.Error(t, err)
+}
+
+func TestSetDelete(t *testing.T) {
+	doc := map[string]interface{}{
+		"a": map[string]interface{}{"b": 1},
+		"l": []interface{}{1, 2},
+	}
+
+	assert.NoError(t, json.Set(doc, "/a/c", "x"))
+	assert.NoError(t, json.Set(doc, "/l/0", 10))
+	assert.NoError(t, json.Set(doc, "/l/-", 3))
+	assert.EqualValues(t, map[string]interface{}{
+		"a": map[string]interface{}{"b": 1, "c": "x"},
+		"l": []interface{}{10, 2, 3},
+	}, doc)
+
+	assert.NoError(t, json.Delete(doc, "/a/b"))
+	assert.NoError(t, json.Delete(doc, "/l/0"))
+	assert.EqualValues(t, map[string]interface{}{
+		"a": map[string]interface{}{"c": "x"},
+		"l": []interface{}{2, 3},
+	}, doc)
+
+	assert.True(t, errors.Is(json.Set(doc, "", 1), json.ErrInvalidPointer))
+	assert.True(t, errors.Is(json.Delete(doc, ""), json.ErrInvalidPointer))
+	assert.True(t, errors.Is(json.Set(doc, "/x/y", 1), json.ErrNotFound))
+	assert.True(t, errors.Is(json.Delete(doc, "/x"), json.ErrNotFound))
+}
+
+func TestPointer(t *testing.T) {
+	assert.EqualValues(t, "", json.Pointer())
+	assert.EqualValues(t, "/a~1b/m~0n/0", json.Pointer("a/b", "m~n", "0"))
+
+	raw, err := json.GetPointer([]byte(rfc6901), json.Pointer("a/b"))
+	assert.NoError(t, err)
+	assert.EqualValues(t, "1", string(raw))
+}
@@ -0,0 +1,688 @@
+package json
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidQuery is returned for a syntax error of JSONPath query.
+var ErrInvalidQuery = errors.New("json: invalid query")
+
+// JSONPath is a compiled query of a small JSONPath subset:
+//
+//	$                root
+//	.name ['name']   object member
+//	[0] [-1]         array element, negative index counts from the end
+//	.* [*]           all members or elements
+//	[start:end:step] array slice, every part is optional
+//	..name ..*       descendants
+//	[?(filter)]      elements or members that match the filter
+//
+// A filter compares relative paths from current value '@' (or absolute ones from '$') with literals by ==, !=, <,
+// <=, >, >= and =~ (regular expression), tests existence by the path alone, and combines them by !, && , || and
+// parentheses:
+//
+//	$.store.book[?(@.price < 10 && @.category == 'fiction')].title
+//
+// Members of an object are visited in key order.
+type JSONPath struct {
+	expr      string
+	selectors []selector
+}
+
+type (
+	// selectorKind is a kind of selector.
+	selectorKind int
+
+	// selector selects values from a value.
+	selector struct {
+		kind       selectorKind
+		name       string
+		index      int
+		start, end *int
+		step       int
+		filter     filterNode
+		descendant bool // applied to the value and all its descendants.
+	}
+)
+
+const (
+	selectName selectorKind = iota
+	selectIndex
+	selectWildcard
+	selectSlice
+	selectFilter
+)
+
+// CompileJSONPath parses given JSONPath query.
+func CompileJSONPath(expr string) (*JSONPath, error) {
+	s := &scanner{src: expr}
+	s.skipSpace()
+	if !s.consume("$") {
+		return nil, s.errorf("query must start with '$'")
+	}
+	sels, err := s.selectors()
+	if err != nil {
+		return nil, err
+	}
+	if s.skipSpace(); s.pos < len(s.src) {
+		return nil, s.errorf("unexpected %q", s.src[s.pos:])
+	}
+	return &JSONPath{expr: expr, selectors: sels}, nil
+}
+
+// MustCompileJSONPath is like CompileJSONPath but panics on error.
+func MustCompileJSONPath(expr string) *JSONPath {
+	p, err := CompileJSONPath(expr)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// String returns the source of the query.
+func (p *JSONPath) String() string {
+	return p.expr
+}
+
+// Select returns values that the query matches in given decoded document such as map[string]interface{}.
+func (p *JSONPath) Select(doc interface{}) []interface{} {
+	return selectAll(p.selectors, doc, doc)
+}
+
+// Query returns values that given JSONPath query matches in given JSON document.
+func Query(doc []byte, expr string) ([]interface{}, error) {
+	p, err := CompileJSONPath(expr)
+	if err != nil {
+		return nil, err
+	}
+	d, err := decodeDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+	return p.Select(d), nil
+}
+
+// QueryAs returns values that given JSONPath query matches in given JSON document as type T.
+func QueryAs[T any](doc []byte, expr string) ([]T, error) {
+	vs, err := Query(doc, expr)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]T, 0, len(vs))
+	for _, v := range vs {
+		t, err := convertTo[T](v)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, t)
+	}
+	return ret, nil
+}
+
+// QueryOne returns the first value that given JSONPath query matches in given JSON document as type T, or
+// ErrNotFound when nothing matches.
+func QueryOne[T any](doc []byte, expr string) (T, error) {
+	vs, err := QueryAs[T](doc, expr)
+	if err != nil || len(vs) == 0 {
+		var zero T
+		if err == nil {
+			err = fmt.Errorf("%w: %s", ErrNotFound, expr)
+		}
+		return zero, err
+	}
+	return vs[0], nil
+}
+
+// selectAll applies given selectors to given value in order.
+func selectAll(sels []selector, cur, root interface{}) []interface{} {
+	nodes := []interface{}{cur}
+	for _, sel := range sels {
+		var next []interface{}
+		for _, n := range nodes {
+			if sel.descendant {
+				for _, d := range descendants(n, nil) {
+					next = append(next, sel.apply(d, root)...)
+				}
+				continue
+			}
+			next = append(next, sel.apply(n, root)...)
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+// descendants appends given value and all its descendants to given slice.
+func descendants(v interface{}, ret []interface{}) []interface{} {
+	ret = append(ret, v)
+	switch d := v.(type) {
+	case map[string]interface{}:
+		for _, k := range sortedKeys(d) {
+			ret = descendants(d[k], ret)
+		}
+	case []interface{}:
+		for _, e := range d {
+			ret = descendants(e, ret)
+		}
+	}
+	return ret
+}
+
+// children returns members or elements of given value.
+func children(v interface{}) []interface{} {
+	switch d := v.(type) {
+	case map[string]interface{}:
+		ret := make([]interface{}, 0, len(d))
+		for _, k := range sortedKeys(d) {
+			ret = append(ret, d[k])
+		}
+		return ret
+	case []interface{}:
+		return d
+	}
+	return nil
+}
+
+// apply returns values selected from given value.
+func (s *selector) apply(v, root interface{}) []interface{} {
+	switch s.kind {
+	case selectName:
+		if m, ok := v.(map[string]interface{}); ok {
+			if e, ok := m[s.name]; ok {
+				return []interface{}{e}
+			}
+		}
+	case selectIndex:
+		if l, ok := v.([]interface{}); ok {
+			i := s.index
+			if i < 0 {
+				i += len(l)
+			}
+			if 0 <= i && i < len(l) {
+				return []interface{}{l[i]}
+			}
+		}
+	case selectWildcard:
+		return children(v)
+	case selectSlice:
+		if l, ok := v.([]interface{}); ok {
+			return s.slice(l)
+		}
+	case selectFilter:
+		var ret []interface{}
+		for _, c := range children(v) {
+			if s.filter.match(c, root) {
+				ret = append(ret, c)
+			}
+		}
+		return ret
+	}
+	return nil
+}
+
+// slice returns elements of given array selected by the slice selector like Python.
+func (s *selector) slice(l []interface{}) []interface{} {
+	n := len(l)
+	bound := func(p *int, def int) int {
+		if p == nil {
+			return def
+		}
+		i := *p
+		if i < 0 {
+			i += n
+		}
+		lo, hi := 0, n
+		if s.step < 0 {
+			lo, hi = -1, n-1
+		}
+		return min(max(i, lo), hi)
+	}
+	var ret []interface{}
+	if s.step > 0 {
+		for i := bound(s.start, 0); i < bound(s.end, n); i += s.step {
+			ret = append(ret, l[i])
+		}
+	} else {
+		for i := bound(s.start, n-1); i > bound(s.end, -1); i += s.step {
+			ret = append(ret, l[i])
+		}
+	}
+	return ret
+}
+
+// scanner parses JSONPath query.
+type scanner struct {
+	src string
+	pos int
+}
+
+// errorf returns ErrInvalidQuery with the position.
+func (s *scanner) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s at %d of %q", ErrInvalidQuery, fmt.Sprintf(format, args...), s.pos, s.src)
+}
+
+func (s *scanner) skipSpace() {
+	for s.pos < len(s.src) && (s.src[s.pos] == ' ' || s.src[s.pos] == '\t') {
+		s.pos++
+	}
+}
+
+// consume skips given string when it comes next.
+func (s *scanner) consume(str string) bool {
+	if strings.HasPrefix(s.src[s.pos:], str) {
+		s.pos += len(str)
+		return true
+	}
+	return false
+}
+
+// peek returns next character, 0 at the end.
+func (s *scanner) peek() byte {
+	if s.pos < len(s.src) {
+		return s.src[s.pos]
+	}
+	return 0
+}
+
+// selectors parses selectors while they continue.
+func (s *scanner) selectors() ([]selector, error) {
+	var ret []selector
+	for {
+		var sel selector
+		switch {
+		case s.consume(".."):
+			sel.descendant = true
+			if s.peek() == '[' {
+				s.pos++
+				if err := s.bracket(&sel); err != nil {
+					return nil, err
+				}
+			} else if err := s.dotName(&sel); err != nil {
+				return nil, err
+			}
+		case s.consume("."):
+			if err := s.dotName(&sel); err != nil {
+				return nil, err
+			}
+		case s.consume("["):
+			if err := s.bracket(&sel); err != nil {
+				return nil, err
+			}
+		default:
+			return ret, nil
+		}
+		ret = append(ret, sel)
+	}
+}
+
+// dotName parses a member name or '*' after dot.
+func (s *scanner) dotName(sel *selector) error {
+	if s.consume("*") {
+		sel.kind = selectWildcard
+		return nil
+	}
+	start := s.pos
+	for s.pos < len(s.src) && !strings.ContainsRune(".[]() \t!=<>&|~", rune(s.src[s.pos])) {
+		s.pos++
+	}
+	if s.pos == start {
+		return s.errorf("member name expected")
+	}
+	sel.kind, sel.name = selectName, s.src[start:s.pos]
+	return nil
+}
+
+// bracket parses a selector in brackets after '['.
+func (s *scanner) bracket(sel *selector) error {
+	s.skipSpace()
+	switch c := s.peek(); {
+	case c == '*':
+		s.pos++
+		sel.kind = selectWildcard
+	case c == '\'' || c == '"':
+		name, err := s.quoted()
+		if err != nil {
+			return err
+		}
+		sel.kind, sel.name = selectName, name
+	case c == '?':
+		s.pos++
+		f, err := s.orExpr()
+		if err != nil {
+			return err
+		}
+		sel.kind, sel.filter = selectFilter, f
+	default:
+		if err := s.indexOrSlice(sel); err != nil {
+			return err
+		}
+	}
+	s.skipSpace()
+	if !s.consume("]") {
+		return s.errorf("']' expected")
+	}
+	return nil
+}
+
+// integer parses an optional signed integer.
+func (s *scanner) integer() (*int, error) {
+	s.skipSpace()
+	start := s.pos
+	if c := s.peek(); c == '-' || c == '+' {
+		s.pos++
+	}
+	for s.pos < len(s.src) && '0' <= s.src[s.pos] && s.src[s.pos] <= '9' {
+		s.pos++
+	}
+	if s.pos == start {
+		return nil, nil
+	}
+	i, err := strconv.Atoi(s.src[start:s.pos])
+	if err != nil {
+		return nil, s.errorf("bad integer %q", s.src[start:s.pos])
+	}
+	s.skipSpace()
+	return &i, nil
+}
+
+// indexOrSlice parses an index or slice.
+func (s *scanner) indexOrSlice(sel *selector) error {
+	start, err := s.integer()
+	if err != nil {
+		return err
+	}
+	if !s.consume(":") {
+		if start == nil {
+			return s.errorf("index expected")
+		}
+		sel.kind, sel.index = selectIndex, *start
+		return nil
+	}
+	end, err := s.integer()
+	if err != nil {
+		return err
+	}
+	sel.kind, sel.start, sel.end, sel.step = selectSlice, start, end, 1
+	if s.consume(":") {
+		step, err := s.integer()
+		if err != nil {
+			return err
+		}
+		if step != nil {
+			if *step == 0 {
+				return s.errorf("slice step must not be 0")
+			}
+			sel.step = *step
+		}
+	}
+	return nil
+}
+
+// quoted parses a quoted string.
+func (s *scanner) quoted() (string, error) {
+	q := s.src[s.pos]
+	var sb strings.Builder
+	for i := s.pos + 1; i < len(s.src); i++ {
+		switch c := s.src[i]; {
+		case c == '\\' && i+1 < len(s.src):
+			i++
+			sb.WriteByte(s.src[i])
+		case c == q:
+			s.pos = i + 1
+			return sb.String(), nil
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return "", s.errorf("unterminated string")
+}
+
+type (
+	// filterNode is a boolean expression of filter.
+	filterNode interface {
+		match(cur, root interface{}) bool
+	}
+
+	// operand is a path or literal in filter.
+	operand struct {
+		root      bool // path from '$' instead of '@'.
+		selectors []selector
+		isPath    bool
+		literal   interface{}
+	}
+
+	// logicalNode is && or || of filters.
+	logicalNode struct {
+		and         bool
+		left, right filterNode
+	}
+
+	// notNode is negation of filter.
+	notNode struct {
+		node filterNode
+	}
+
+	// existNode matches when the path selects something.
+	existNode struct {
+		path operand
+	}
+
+	// compareNode compares two operands.
+	compareNode struct {
+		op          string
+		left, right operand
+		re          *regexp.Regexp
+	}
+)
+
+func (n *logicalNode) match(cur, root interface{}) bool {
+	if n.and {
+		return n.left.match(cur, root) && n.right.match(cur, root)
+	}
+	return n.left.match(cur, root) || n.right.match(cur, root)
+}
+
+func (n *notNode) match(cur, root interface{}) bool {
+	return !n.node.match(cur, root)
+}
+
+func (n *existNode) match(cur, root interface{}) bool {
+	_, ok := n.path.value(cur, root)
+	return ok
+}
+
+func (n *compareNode) match(cur, root interface{}) bool {
+	l, ok := n.left.value(cur, root)
+	if !ok {
+		return false
+	}
+	if n.re != nil {
+		s, ok := l.(string)
+		return ok && n.re.MatchString(s)
+	}
+	r, ok := n.right.value(cur, root)
+	if !ok {
+		return false
+	}
+	switch n.op {
+	case "==":
+		return equalValues(l, r)
+	case "!=":
+		return !equalValues(l, r)
+	}
+	c, ok := compareOrdered(l, r)
+	if !ok {
+		return false
+	}
+	switch n.op {
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	}
+	return c >= 0
+}
+
+// compareOrdered compares two numbers or two strings.
+func compareOrdered(a, b interface{}) (int, bool) {
+	if x, ok := a.(string); ok {
+		y, ok := b.(string)
+		return strings.Compare(x, y), ok
+	}
+	c := compareNumbers(a, b)
+	return c, c != -2
+}
+
+// value returns the value of the operand, false when the path selects nothing.
+func (o *operand) value(cur, root interface{}) (interface{}, bool) {
+	if !o.isPath {
+		return o.literal, true
+	}
+	start := cur
+	if o.root {
+		start = root
+	}
+	vs := selectAll(o.selectors, start, root)
+	if len(vs) == 0 {
+		return nil, false
+	}
+	return vs[0], true
+}
+
+// orExpr parses filter expression joined by ||.
+func (s *scanner) orExpr() (filterNode, error) {
+	left, err := s.andExpr()
+	if err != nil {
+		return nil, err
+	}
+	for s.skipSpace(); s.consume("||"); s.skipSpace() {
+		right, err := s.andExpr()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+// andExpr parses filter expression joined by &&.
+func (s *scanner) andExpr() (filterNode, error) {
+	left, err := s.unaryExpr()
+	if err != nil {
+		return nil, err
+	}
+	for s.skipSpace(); s.consume("&&"); s.skipSpace() {
+		right, err := s.unaryExpr()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+// comparisonOps are comparison operators, longer ones first.
+var comparisonOps = []string{"==", "!=", "<=", ">=", "=~", "<", ">"}
+
+// unaryExpr parses negation, parenthesized expression, comparison or existence test.
+func (s *scanner) unaryExpr() (filterNode, error) {
+	s.skipSpace()
+	if s.peek() == '!' && !strings.HasPrefix(s.src[s.pos:], "!=") {
+		s.pos++
+		n, err := s.unaryExpr()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{node: n}, nil
+	}
+	if s.consume("(") {
+		n, err := s.orExpr()
+		if err != nil {
+			return nil, err
+		}
+		s.skipSpace()
+		if !s.consume(")") {
+			return nil, s.errorf("')' expected")
+		}
+		return n, nil
+	}
+
+	left, err := s.operand()
+	if err != nil {
+		return nil, err
+	}
+	s.skipSpace()
+	op := ""
+	for _, o := range comparisonOps {
+		if s.consume(o) {
+			op = o
+			break
+		}
+	}
+	if op == "" {
+		if !left.isPath {
+			return nil, s.errorf("comparison operator expected")
+		}
+		return &existNode{path: left}, nil
+	}
+	right, err := s.operand()
+	if err != nil {
+		return nil, err
+	}
+	n := &compareNode{op: op, left: left, right: right}
+	if op == "=~" {
+		pattern, ok := right.literal.(string)
+		if right.isPath || !ok {
+			return nil, s.errorf("=~ needs a string literal")
+		}
+		if n.re, err = regexp.Compile(pattern); err != nil {
+			return nil, s.errorf("%v", err)
+		}
+	}
+	return n, nil
+}
+
+// operand parses a path or literal.
+func (s *scanner) operand() (operand, error) {
+	s.skipSpace()
+	var o operand
+	switch c := s.peek(); {
+	case c == '@' || c == '$':
+		s.pos++
+		sels, err := s.selectors()
+		if err != nil {
+			return o, err
+		}
+		o.isPath, o.root, o.selectors = true, c == '$', sels
+	case c == '\'' || c == '"':
+		str, err := s.quoted()
+		if err != nil {
+			return o, err
+		}
+		o.literal = str
+	case s.consume("true"):
+		o.literal = true
+	case s.consume("false"):
+		o.literal = false
+	case s.consume("null"):
+		o.literal = nil
+	default:
+		start := s.pos
+		for s.pos < len(s.src) && strings.IndexByte("+-0123456789.eE", s.src[s.pos]) >= 0 {
+			s.pos++
+		}
+		num := s.src[start:s.pos]
+		if _, ok := toRat(json.Number(num)); !ok || num == "" {
+			return o, s.errorf("operand expected")
+		}
+		o.literal = json.Number(num)
+	}
+	return o, nil
+}
@@ -0,0 +1,130 @@
+package json_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/marrbor/goutil/encoding/json"
+	"github.com/stretchr/testify/assert"
+)
+
+// store is the example document of JSONPath.
+const store = `{
+  "store": {
+    "book": [
+      {"category": "reference", "author": "Nigel Rees", "title": "Sayings of the Century", "price": 8.95},
+      {"category": "fiction", "author": "Evelyn Waugh", "title": "Sword of Honour", "price": 12.99},
+      {"category": "fiction", "author": "Herman Melville", "title": "Moby Dick", "isbn": "0-553-21311-3", "price": 8.99},
+      {"category": "fiction", "author": "J. R. R. Tolkien", "title": "The Lord of the Rings", "isbn": "0-395-19395-8", "price": 22.99}
+    ],
+    "bicycle": {"color": "red", "price": 19.95}
+  },
+  "expensive": 10
+}`
+
+func TestQueryAs(t *testing.T) {
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{`$.store.book[*].author`, []string{"Nigel Rees", "Evelyn Waugh", "Herman Melville", "J. R. R. Tolkien"}},
+		{`$..author`, []string{"Nigel Rees", "Evelyn Waugh", "Herman Melville", "J. R. R. Tolkien"}},
+		{`$['store']["bicycle"].color`, []string{"red"}},
+		{`$.store.book[2].title`, []string{"Moby Dick"}},
+		{`$.store.book[-1].title`, []string{"The Lord of the Rings"}},
+		{`$.store.book[:2].title`, []string{"Sayings of the Century", "Sword of Honour"}},
+		{`$.store.book[1:3].title`, []string{"Sword of Honour", "Moby Dick"}},
+		{`$.store.book[-2:].title`, []string{"Moby Dick", "The Lord of the Rings"}},
+		{`$.store.book[::2].title`, []string{"Sayings of the Century", "Moby Dick"}},
+		{`$.store.book[::-1].title`, []string{"The Lord of the Rings", "Moby Dick", "Sword of Honour", "Sayings of the Century"}},
+		{`$.store.book[2:0:-1].title`, []string{"Moby Dick", "Sword of Honour"}},
+		{`$.store.book[10:].title`, nil},
+		{`$.store.book[?(@.isbn)].title`, []string{"Moby Dick", "The Lord of the Rings"}},
+		{`$.store.book[?(!@.isbn)].title`, []string{"Sayings of the Century", "Sword of Honour"}},
+		{`$.store.book[?(@.price < 10)].title`, []string{"Sayings of the Century", "Moby Dick"}},
+		{`$.store.book[?(@.price > $.expensive)].title`, []string{"Sword of Honour", "The Lord of the Rings"}},
+		{`$.store.book[?(@.price < 10 && @.category == 'fiction')].title`, []string{"Moby Dick"}},
+		{`$.store.book[?(@.price > 20 || @.category == "reference")].title`, []string{"Sayings of the Century", "The Lord of the Rings"}},
+		{`$.store.book[?(!(@.category == 'fiction') || @.price == 8.99)].title`, []string{"Sayings of the Century", "Moby Dick"}},
+		{`$.store.book[?(@.author =~ '^J\\. R')].title`, []string{"The Lord of the Rings"}},
+		{`$.store.book[?(@.category != 'fiction')].title`, []string{"Sayings of the Century"}},
+		{`$.store.book[?(@.title >= 'S')].title`, []string{"Sayings of the Century", "Sword of Honour", "The Lord of the Rings"}},
+		{`$..book[?(@.price <= 8.99)].author`, []string{"Nigel Rees", "Herman Melville"}},
+		{`$.store.*.color`, []string{"red"}},
+		{`$.nothing`, nil},
+	}
+	for _, tt := range tests {
+		got, err := json.QueryAs[string]([]byte(store), tt.expr)
+		assert.NoError(t, err, tt.expr)
+		assert.EqualValues(t, len(tt.want), len(got), tt.expr)
+		if tt.want != nil {
+			assert.EqualValues(t, tt.want, got, tt.expr)
+		}
+	}
+}
+
+func TestQuery(t *testing.T) {
+	vs, err := json.Query([]byte(store), `$..price`)
+	assert.NoError(t, err)
+	// four books and the bicycle.
+	assert.EqualValues(t, 5, len(vs))
+
+	vs, err = json.Query([]byte(store), `$.store.*`)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, len(vs))
+
+	vs, err = json.Query([]byte(store), `$`)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, len(vs))
+
+	_, err = json.Query([]byte(`{`), `$`)
+	assert.Error(t, err)
+}
+
+func TestQueryOne(t *testing.T) {
+	type book struct {
+		Title string  `json:"title"`
+		Price float64 `json:"price"`
+	}
+	b, err := json.QueryOne[book]([]byte(store), `$.store.book[?(@.isbn == '0-553-21311-3')]`)
+	assert.NoError(t, err)
+	assert.EqualValues(t, book{Title: "Moby Dick", Price: 8.99}, b)
+
+	p, err := json.QueryOne[float64]([]byte(store), `$.store.bicycle.price`)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 19.95, p)
+
+	_, err = json.QueryOne[book]([]byte(store), `$.store.book[?(@.price > 100)]`)
+	assert.True(t, errors.Is(err, json.ErrNotFound))
+	_, err = json.QueryOne[int]([]byte(store), `$.store.bicycle.color`)
+	assert.Error(t, err)
+}
+
+func TestCompileJSONPath(t *testing.T) {
+	for _, expr := range []string{
+		``,
+		`store`,
+		`$.`,
+		`$[`,
+		`$[0`,
+		`$['a`,
+		`$[a]`,
+		`$[::0]`,
+		`$[?(@.a <)]`,
+		`$[?(@.a =~ @.b)]`,
+		`$[?(@.a =~ '(')]`,
+		`$[?(1)]`,
+		`$[?((@.a)]`,
+		`$.a b`,
+	} {
+		_, err := json.CompileJSONPath(expr)
+		assert.True(t, errors.Is(err, json.ErrInvalidQuery), expr)
+	}
+
+	p := json.MustCompileJSONPath(`$.a[0]`)
+	assert.EqualValues(t, `$.a[0]`, p.String())
+	doc := map[string]interface{}{"a": []interface{}{"x", "y"}}
+	assert.EqualValues(t, []interface{}{"x"}, p.Select(doc))
+
+	assert.Panics(t, func() { json.MustCompileJSONPath(`a`) })
+}
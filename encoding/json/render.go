@@ -0,0 +1,315 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// RedactTag is the struct tag that marks a field to be redacted by Redact option: `redact:"true"`.
+const RedactTag = "redact"
+
+// RedactedValue replaces the values of redacted fields.
+const RedactedValue = "[REDACTED]"
+
+// DefaultRedactKeys matches keys that Redact option redacts regardless of tags.
+var DefaultRedactKeys = regexp.MustCompile(`(?i)passw(or)?d|token|secret`)
+
+type (
+	// RenderOption is an option of JSONString.
+	RenderOption func(*renderConfig)
+
+	// ColorScheme has ANSI escape sequences for each kind of token. Empty one leaves the token uncolored.
+	ColorScheme struct {
+		Key    string
+		String string
+		Number string
+		Bool   string
+		Null   string
+	}
+
+	renderConfig struct {
+		indent     string
+		sortKeys   bool
+		escapeHTML bool
+		colors     ColorScheme
+		redact     bool
+		redactKeys *regexp.Regexp
+	}
+)
+
+// DefaultColors is the color scheme of Colored option.
+var DefaultColors = ColorScheme{
+	Key:    "\x1b[34;1m", // bold blue
+	String: "\x1b[32m",   // green
+	Number: "\x1b[36m",   // cyan
+	Bool:   "\x1b[33m",   // yellow
+	Null:   "\x1b[90m",   // gray
+}
+
+// colorReset resets ANSI color.
+const colorReset = "\x1b[0m"
+
+// Indent makes JSONString output each member and element in its own line indented by given string like
+// json.MarshalIndent.
+func Indent(indent string) RenderOption {
+	return func(c *renderConfig) { c.indent = indent }
+}
+
+// SortKeys makes JSONString output object members sorted by key, including fields of structures.
+func SortKeys() RenderOption {
+	return func(c *renderConfig) { c.sortKeys = true }
+}
+
+// NoEscapeHTML makes JSONString output '<', '>' and '&' as they are instead of escaping them like \u003c.
+func NoEscapeHTML() RenderOption {
+	return func(c *renderConfig) { c.escapeHTML = false }
+}
+
+// Colored makes JSONString output colored by ANSI escape sequences of DefaultColors for terminals.
+func Colored() RenderOption {
+	return WithColors(DefaultColors)
+}
+
+// WithColors makes JSONString output colored by given color scheme.
+func WithColors(s ColorScheme) RenderOption {
+	return func(c *renderConfig) { c.colors = s }
+}
+
+// Redact makes JSONString replace the values of fields tagged with `redact:"true"` and members whose keys match
+// DefaultRedactKeys with RedactedValue, so that the output can be logged safely.
+func Redact() RenderOption {
+	return RedactKeys(DefaultRedactKeys)
+}
+
+// RedactKeys is like Redact but redacts members whose keys match given pattern instead of DefaultRedactKeys. nil
+// redacts tagged fields only.
+func RedactKeys(pattern *regexp.Regexp) RenderOption {
+	return func(c *renderConfig) {
+		c.redact = true
+		c.redactKeys = pattern
+	}
+}
+
+// render returns JSON of given value rendered with the options.
+func (c *renderConfig) render(v interface{}) ([]byte, error) {
+	if c.redact {
+		r, err := redactValue(reflect.ValueOf(v))
+		if err != nil {
+			return nil, err
+		}
+		v = r
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(c.escapeHTML)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(&buf)
+	dec.UseNumber()
+	doc, err := readOrdered(dec)
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	c.write(&out, doc, 0)
+	return out.Bytes(), nil
+}
+
+// readOrdered reads a value from given decoder keeping the order of object members.
+func readOrdered(dec *json.Decoder) (interface{}, error) {
+	t, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t {
+	case json.Delim('{'):
+		obj := orderedObject{}
+		for dec.More() {
+			k, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			v, err := readOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, objectField{name: k.(string), value: v})
+		}
+		_, err := dec.Token() // '}'
+		return obj, err
+	case json.Delim('['):
+		arr := []interface{}{}
+		for dec.More() {
+			e, err := readOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, e)
+		}
+		_, err := dec.Token() // ']'
+		return arr, err
+	}
+	return t, nil
+}
+
+// write writes given value read by readOrdered at given depth.
+func (c *renderConfig) write(w *bytes.Buffer, v interface{}, depth int) {
+	newline := func(depth int) {
+		if c.indent != "" {
+			w.WriteByte('\n')
+			w.WriteString(strings.Repeat(c.indent, depth))
+		}
+	}
+
+	switch x := v.(type) {
+	case orderedObject:
+		if len(x) == 0 {
+			w.WriteString("{}")
+			return
+		}
+		if c.sortKeys {
+			sort.SliceStable(x, func(i, j int) bool { return x[i].name < x[j].name })
+		}
+		w.WriteByte('{')
+		for i, f := range x {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			newline(depth + 1)
+			c.colored(w, c.colors.Key, c.quote(f.name))
+			w.WriteByte(':')
+			if c.indent != "" {
+				w.WriteByte(' ')
+			}
+			if c.redactKeys != nil && c.redactKeys.MatchString(f.name) {
+				c.write(w, RedactedValue, depth+1)
+				continue
+			}
+			c.write(w, f.value, depth+1)
+		}
+		newline(depth)
+		w.WriteByte('}')
+	case []interface{}:
+		if len(x) == 0 {
+			w.WriteString("[]")
+			return
+		}
+		w.WriteByte('[')
+		for i, e := range x {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			newline(depth + 1)
+			c.write(w, e, depth+1)
+		}
+		newline(depth)
+		w.WriteByte(']')
+	case string:
+		c.colored(w, c.colors.String, c.quote(x))
+	case json.Number:
+		c.colored(w, c.colors.Number, string(x))
+	case bool:
+		s := "false"
+		if x {
+			s = "true"
+		}
+		c.colored(w, c.colors.Bool, s)
+	default:
+		c.colored(w, c.colors.Null, "null")
+	}
+}
+
+// colored writes given token in given color.
+func (c *renderConfig) colored(w *bytes.Buffer, color, token string) {
+	if color == "" {
+		w.WriteString(token)
+		return
+	}
+	w.WriteString(color)
+	w.WriteString(token)
+	w.WriteString(colorReset)
+}
+
+// quote returns JSON string of given string.
+func (c *renderConfig) quote(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(c.escapeHTML)
+	_ = enc.Encode(s) // never fails for a string.
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
+
+var redactedTypes sync.Map // reflect.Type => bool
+
+// redactValue returns a value to be marshaled instead of given value, whose fields tagged with `redact:"true"` are
+// replaced with RedactedValue. Values in interfaces are redacted by their dynamic types.
+func redactValue(v reflect.Value) (interface{}, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+	if !hasTagged(v.Type(), &redactedTypes, func(f fieldInfo) bool { return f.redact }) {
+		return v.Interface(), nil
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return redactValue(v.Elem())
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil, nil
+		}
+		ret := make([]interface{}, v.Len())
+		for i := range ret {
+			r, err := redactValue(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			ret[i] = r
+		}
+		return ret, nil
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, nil
+		}
+		ret := make(map[string]interface{}, v.Len())
+		for it := v.MapRange(); it.Next(); {
+			k, err := mapKey(it.Key())
+			if err != nil {
+				return nil, err
+			}
+			r, err := redactValue(it.Value())
+			if err != nil {
+				return nil, err
+			}
+			ret[k] = r
+		}
+		return ret, nil
+	}
+
+	// struct
+	var ret orderedObject
+	for _, f := range structFields(v.Type()) {
+		fv, ok := fieldByIndex(v, f.index)
+		if !ok || (f.omitEmpty && isEmptyValue(fv)) {
+			continue
+		}
+		if f.redact {
+			ret = append(ret, objectField{name: f.name, value: RedactedValue})
+			continue
+		}
+		r, err := redactValue(fv)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, objectField{name: f.name, value: r})
+	}
+	return ret, nil
+}
@@ -0,0 +1,156 @@
+package json_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/marrbor/goutil/encoding/json"
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	renderCard struct {
+		Number string `json:"number" redact:"true"`
+		Brand  string `json:"brand"`
+	}
+
+	renderRequest struct {
+		User     string            `json:"user"`
+		Password string            `json:"password"`
+		Note     string            `json:"note,omitempty" redact:"true"`
+		Cards    []renderCard      `json:"cards"`
+		Primary  *renderCard       `json:"primary"`
+		Headers  map[string]string `json:"headers"`
+		Count    int               `json:"count"`
+		OK       bool              `json:"ok"`
+		Extra    interface{}       `json:"extra"`
+	}
+)
+
+var renderReq = renderRequest{
+	User:     "<alice>",
+	Password: "p@ss",
+	Cards:    []renderCard{{Number: "4111-1111-1111-1111", Brand: "visa"}},
+	Primary:  &renderCard{Number: "5500-0000-0000-0004", Brand: "master"},
+	Headers:  map[string]string{"X-Api-Token": "abc", "Accept": "*/*"},
+	Count:    2,
+	OK:       true,
+}
+
+func TestJSONString_Options(t *testing.T) {
+	s, err := json.JSONString(renderReq)
+	assert.NoError(t, err)
+	assert.EqualValues(t, `{"user":"\u003calice\u003e","password":"p@ss","cards":[{"number":"4111-1111-1111-1111","brand":"visa"}],`+
+		`"primary":{"number":"5500-0000-0000-0004","brand":"master"},"headers":{"Accept":"*/*","X-Api-Token":"abc"},"count":2,"ok":true,"extra":null}`, s)
+
+	s, err = json.JSONString(renderReq, json.NoEscapeHTML())
+	assert.NoError(t, err)
+	assert.Contains(t, s, `{"user":"<alice>","password":"p@ss",`)
+
+	s, err = json.JSONString(renderReq, json.Redact(), json.NoEscapeHTML())
+	assert.NoError(t, err)
+	assert.EqualValues(t, `{"user":"<alice>","password":"[REDACTED]","cards":[{"number":"[REDACTED]","brand":"visa"}],`+
+		`"primary":{"number":"[REDACTED]","brand":"master"},"headers":{"Accept":"*/*","X-Api-Token":"[REDACTED]"},"count":2,"ok":true,"extra":null}`, s)
+
+	// tagged fields only.
+	s, err = json.JSONString(renderRequest{Password: "p@ss", Note: "private"}, json.RedactKeys(nil), json.SortKeys())
+	assert.NoError(t, err)
+	assert.EqualValues(t, `{"cards":null,"count":0,"extra":null,"headers":null,"note":"[REDACTED]","ok":false,"password":"p@ss","primary":null,"user":""}`, s)
+
+	s, err = json.JSONString(map[string]interface{}{"Secret": 1, "session": map[string]string{"id": "x"}},
+		json.RedactKeys(regexp.MustCompile(`^(?i)(secret|id)$`)))
+	assert.NoError(t, err)
+	assert.EqualValues(t, `{"Secret":"[REDACTED]","session":{"id":"[REDACTED]"}}`, s)
+
+	// the value is not modified.
+	assert.EqualValues(t, "4111-1111-1111-1111", renderReq.Cards[0].Number)
+
+	_, err = json.JSONString(make(chan int), json.SortKeys())
+	assert.Error(t, err)
+}
+
+func TestJSONString_RedactDynamic(t *testing.T) {
+	card := renderCard{Number: "4111-1111-1111-1111", Brand: "visa"}
+
+	// tagged fields in interface{} payloads.
+	s, err := json.JSONString(map[string]interface{}{"card": card, "list": []interface{}{&card, 1}}, json.RedactKeys(nil))
+	assert.NoError(t, err)
+	assert.EqualValues(t, `{"card":{"number":"[REDACTED]","brand":"visa"},"list":[{"number":"[REDACTED]","brand":"visa"},1]}`, s)
+
+	s, err = json.JSONString([]interface{}{card}, json.RedactKeys(nil))
+	assert.NoError(t, err)
+	assert.EqualValues(t, `[{"number":"[REDACTED]","brand":"visa"}]`, s)
+
+	s, err = json.JSONString(renderRequest{Extra: card}, json.Redact())
+	assert.NoError(t, err)
+	assert.Contains(t, s, `"extra":{"number":"[REDACTED]","brand":"visa"}`)
+
+	// non-string map keys.
+	s, err = json.JSONString(map[int]interface{}{1: card}, json.RedactKeys(nil))
+	assert.NoError(t, err)
+	assert.EqualValues(t, `{"1":{"number":"[REDACTED]","brand":"visa"}}`, s)
+
+	_, err = json.JSONString(map[[1]int]interface{}{{1}: card}, json.Redact())
+	assert.Error(t, err)
+}
+
+type (
+	redactCycleA struct {
+		B *redactCycleB `json:"b"`
+	}
+
+	redactCycleB struct {
+		A    *redactCycleA `json:"a"`
+		Card string        `json:"card" redact:"true"`
+	}
+)
+
+func TestJSONString_RedactRecursive(t *testing.T) {
+	v := redactCycleB{A: &redactCycleA{B: &redactCycleB{Card: "4111-1111"}}, Card: "5500-0000"}
+	s, err := json.JSONString(v, json.RedactKeys(nil))
+	assert.NoError(t, err)
+	assert.EqualValues(t, `{"a":{"b":{"a":null,"card":"[REDACTED]"}},"card":"[REDACTED]"}`, s)
+
+	s, err = json.JSONString(redactCycleA{B: &redactCycleB{Card: "4111-1111"}}, json.RedactKeys(nil))
+	assert.NoError(t, err)
+	assert.EqualValues(t, `{"b":{"a":null,"card":"[REDACTED]"}}`, s)
+}
+
+func TestJSONString_Indent(t *testing.T) {
+	v := map[string]interface{}{"b": []interface{}{1, "x", nil}, "a": map[string]interface{}{}, "c": []int{}, "d": 1.5}
+	s, err := json.JSONString(v, json.Indent("  "))
+	assert.NoError(t, err)
+	assert.EqualValues(t, `{
+  "a": {},
+  "b": [
+    1,
+    "x",
+    null
+  ],
+  "c": [],
+  "d": 1.5
+}`, s)
+
+	type st struct {
+		Z int `json:"z"`
+		A int `json:"a"`
+	}
+	s, err = json.JSONString(st{Z: 1, A: 2}, json.Indent("\t"))
+	assert.NoError(t, err)
+	assert.EqualValues(t, "{\n\t\"z\": 1,\n\t\"a\": 2\n}", s)
+
+	s, err = json.JSONString(st{Z: 1, A: 2}, json.SortKeys())
+	assert.NoError(t, err)
+	assert.EqualValues(t, `{"a":2,"z":1}`, s)
+}
+
+func TestJSONString_Colored(t *testing.T) {
+	s, err := json.JSONString(map[string]interface{}{"k": "v", "n": 1, "b": false, "z": nil}, json.Colored())
+	assert.NoError(t, err)
+	assert.EqualValues(t, "{\x1b[34;1m\"b\"\x1b[0m:\x1b[33mfalse\x1b[0m,\x1b[34;1m\"k\"\x1b[0m:\x1b[32m\"v\"\x1b[0m,"+
+		"\x1b[34;1m\"n\"\x1b[0m:\x1b[36m1\x1b[0m,\x1b[34;1m\"z\"\x1b[0m:\x1b[90mnull\x1b[0m}", s)
+
+	s, err = json.JSONString([]interface{}{"v", 1}, json.WithColors(json.ColorScheme{Number: "<n>"}))
+	assert.NoError(t, err)
+	assert.EqualValues(t, "[\"v\",<n>1\x1b[0m]", s)
+}
@@ -0,0 +1,327 @@
+package json
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// SchemaVersion is the JSON Schema dialect that Schema implements a subset of.
+const SchemaVersion = "https://json-schema.org/draft/2020-12/schema"
+
+var (
+	ErrInvalidSchema = errors.New("json: invalid schema")
+	ErrValidation    = errors.New("json: validation failed")
+)
+
+type (
+	// SchemaTypes is the "type" keyword of JSON Schema. It is marshaled as a string when it has one type.
+	SchemaTypes []string
+
+	// Schema is a subset of JSON Schema draft 2020-12: type, enum, properties, required, items, minimum, maximum,
+	// minLength, maxLength, minItems, maxItems, pattern and format. Other keywords are ignored. Formats date-time,
+	// date, time, email, hostname, ipv4, ipv6, uri and uuid are checked, and unknown ones are ignored.
+	Schema struct {
+		Schema      string             `json:"$schema,omitempty"`
+		Title       string             `json:"title,omitempty"`
+		Description string             `json:"description,omitempty"`
+		Type        SchemaTypes        `json:"type,omitempty"`
+		Enum        []interface{}      `json:"enum,omitempty"`
+		Properties  map[string]*Schema `json:"properties,omitempty"`
+		Required    []string           `json:"required,omitempty"`
+		Items       *Schema            `json:"items,omitempty"`
+		Minimum     *float64           `json:"minimum,omitempty"`
+		Maximum     *float64           `json:"maximum,omitempty"`
+		MinLength   *int               `json:"minLength,omitempty"`
+		MaxLength   *int               `json:"maxLength,omitempty"`
+		MinItems    *int               `json:"minItems,omitempty"`
+		MaxItems    *int               `json:"maxItems,omitempty"`
+		Pattern     string             `json:"pattern,omitempty"`
+		Format      string             `json:"format,omitempty"`
+	}
+
+	// Violation is a failure of a schema keyword at the JSON Pointer location of the instance.
+	Violation struct {
+		Pointer string `json:"pointer"`
+		Keyword string `json:"keyword"`
+		Message string `json:"message"`
+	}
+
+	// ValidationError has all violations found in a document. It wraps ErrValidation.
+	ValidationError struct {
+		Violations []Violation `json:"violations"`
+	}
+)
+
+// MarshalJSON returns a string for one type and an array for others.
+func (t SchemaTypes) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// UnmarshalJSON accepts a string or an array of strings.
+func (t *SchemaTypes) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*t = SchemaTypes{s}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(t))
+}
+
+// Error returns error string.
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = v.String()
+	}
+	return fmt.Sprintf("%v: %s", ErrValidation, strings.Join(msgs, "; "))
+}
+
+// Unwrap returns ErrValidation.
+func (e *ValidationError) Unwrap() error {
+	return ErrValidation
+}
+
+// String returns the violation with its location.
+func (v Violation) String() string {
+	p := v.Pointer
+	if p == "" {
+		p = "(root)"
+	}
+	return fmt.Sprintf("%s: %s", p, v.Message)
+}
+
+// ParseSchema parses given JSON Schema. Patterns are checked to be valid regular expressions.
+func ParseSchema(data []byte) (*Schema, error) {
+	var s Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchema, err)
+	}
+	if err := s.check(nil); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// MustParseSchema is like ParseSchema but panics on error.
+func MustParseSchema(data []byte) *Schema {
+	s, err := ParseSchema(data)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// check returns an error when a pattern in the schema is invalid.
+func (s *Schema) check(path []string) error {
+	if s.Pattern != "" {
+		if _, err := compilePattern(s.Pattern); err != nil {
+			return fmt.Errorf("%w: pattern at %q: %v", ErrInvalidSchema, formatPointer(path), err)
+		}
+	}
+	for _, k := range sortedSchemaKeys(s.Properties) {
+		if p := s.Properties[k]; p != nil {
+			if err := p.check(append(path[:len(path):len(path)], "properties", k)); err != nil {
+				return err
+			}
+		}
+	}
+	if s.Items != nil {
+		return s.Items.check(append(path[:len(path):len(path)], "items"))
+	}
+	return nil
+}
+
+// Validate validates given JSON document and returns *ValidationError that has all violations when it is invalid.
+func (s *Schema) Validate(doc []byte) error {
+	d, err := decodeDocument(doc)
+	if err != nil {
+		return err
+	}
+	return s.ValidateValue(d)
+}
+
+// ValidateValue validates given decoded document such as map[string]interface{} like Validate.
+func (s *Schema) ValidateValue(doc interface{}) error {
+	if vs := s.validate(doc, nil, nil); len(vs) > 0 {
+		return &ValidationError{Violations: vs}
+	}
+	return nil
+}
+
+// ValidateSchema makes decoding fail with *ValidationError when the input does not satisfy given schema. The input
+// is validated before it is decoded into the value.
+func ValidateSchema(s *Schema) DecodeOption {
+	return func(c *decodeConfig) { c.schema = s }
+}
+
+// validate appends violations of given value at given path to vs.
+func (s *Schema) validate(v interface{}, path []string, vs []Violation) []Violation {
+	add := func(keyword, format string, args ...interface{}) {
+		vs = append(vs, Violation{Pointer: formatPointer(path), Keyword: keyword, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(s.Type) > 0 && !s.Type.match(v) {
+		add("type", "expected %s, got %s", strings.Join(s.Type, " or "), typeName(v))
+		return vs // other keywords are meaningless.
+	}
+	if len(s.Enum) > 0 && !containsValue(s.Enum, v) {
+		add("enum", "must be one of %s", compactJSON(s.Enum))
+	}
+
+	switch x := v.(type) {
+	case string:
+		n := utf8.RuneCountInString(x)
+		if s.MinLength != nil && n < *s.MinLength {
+			add("minLength", "length must be >= %d, got %d", *s.MinLength, n)
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			add("maxLength", "length must be <= %d, got %d", *s.MaxLength, n)
+		}
+		if s.Pattern != "" {
+			if re, err := compilePattern(s.Pattern); err != nil {
+				add("pattern", "invalid pattern %q: %v", s.Pattern, err)
+			} else if !re.MatchString(x) {
+				add("pattern", "must match %q", s.Pattern)
+			}
+		}
+		if check, ok := formats[s.Format]; ok && !check(x) {
+			add("format", "must be %s", s.Format)
+		}
+	case []interface{}:
+		if s.MinItems != nil && len(x) < *s.MinItems {
+			add("minItems", "must have >= %d items, got %d", *s.MinItems, len(x))
+		}
+		if s.MaxItems != nil && len(x) > *s.MaxItems {
+			add("maxItems", "must have <= %d items, got %d", *s.MaxItems, len(x))
+		}
+		if s.Items != nil {
+			for i, e := range x {
+				vs = s.Items.validate(e, append(path[:len(path):len(path)], fmt.Sprint(i)), vs)
+			}
+		}
+	case map[string]interface{}:
+		for _, k := range s.Required {
+			if _, ok := x[k]; !ok {
+				add("required", "missing property %q", k)
+			}
+		}
+		for _, k := range sortedKeys(x) {
+			if p := s.Properties[k]; p != nil {
+				vs = p.validate(x[k], append(path[:len(path):len(path)], k), vs)
+			}
+		}
+	default:
+		if s.Minimum != nil && compareNumbers(v, *s.Minimum) == -1 {
+			add("minimum", "must be >= %v, got %v", *s.Minimum, v)
+		}
+		if s.Maximum != nil && compareNumbers(v, *s.Maximum) == 1 {
+			add("maximum", "must be <= %v, got %v", *s.Maximum, v)
+		}
+	}
+	return vs
+}
+
+// match returns whether given value is one of the types.
+func (t SchemaTypes) match(v interface{}) bool {
+	name := typeName(v)
+	for _, tn := range t {
+		if tn == name {
+			return true
+		}
+		if tn == "integer" && name == "number" {
+			if r, ok := toRat(v); ok && r.IsInt() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// containsValue returns whether given list has a value equal to v.
+func containsValue(list []interface{}, v interface{}) bool {
+	for _, e := range list {
+		if equalValues(e, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedSchemaKeys returns sorted keys of given properties.
+func sortedSchemaKeys(m map[string]*Schema) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var patterns sync.Map // string => *regexp.Regexp
+
+// compilePattern returns compiled regular expression of given pattern, caching it.
+func compilePattern(p string) (*regexp.Regexp, error) {
+	if re, ok := patterns.Load(p); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(p)
+	if err != nil {
+		return nil, err
+	}
+	patterns.Store(p, re)
+	return re, nil
+}
+
+var (
+	hostnameRE = regexp.MustCompile(`^(?i)[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?(\.[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?)*$`)
+	uuidRE     = regexp.MustCompile(`^(?i)[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+)
+
+// formats are checkers of format keyword.
+var formats = map[string]func(string) bool{
+	"date-time": func(s string) bool {
+		_, err := time.Parse(time.RFC3339Nano, s)
+		return err == nil
+	},
+	"date": func(s string) bool {
+		_, err := time.Parse(time.DateOnly, s)
+		return err == nil
+	},
+	"time": func(s string) bool {
+		_, err := time.Parse("15:04:05.999999999Z07:00", s)
+		return err == nil
+	},
+	"email": func(s string) bool {
+		a, err := mail.ParseAddress(s)
+		return err == nil && a.Address == s
+	},
+	"hostname": func(s string) bool {
+		return len(s) <= 253 && hostnameRE.MatchString(s)
+	},
+	"ipv4": func(s string) bool {
+		a, err := netip.ParseAddr(s)
+		return err == nil && a.Is4()
+	},
+	"ipv6": func(s string) bool {
+		a, err := netip.ParseAddr(s)
+		return err == nil && a.Is6()
+	},
+	"uri": func(s string) bool {
+		u, err := url.Parse(s)
+		return err == nil && u.IsAbs()
+	},
+	"uuid": uuidRE.MatchString,
+}
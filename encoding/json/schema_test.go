@@ -0,0 +1,176 @@
+package json_test
+
+import (
+	stdjson "encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/marrbor/goutil/encoding/json"
+	"github.com/stretchr/testify/assert"
+)
+
+const userSchema = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "type": "object",
+  "required": ["id", "name", "tags"],
+  "properties": {
+    "id": {"type": "integer", "minimum": 1},
+    "name": {"type": "string", "minLength": 1, "maxLength": 8, "pattern": "^[a-z]+$"},
+    "email": {"type": "string", "format": "email"},
+    "role": {"enum": ["admin", "user", null]},
+    "score": {"type": ["number", "null"], "minimum": 0, "maximum": 100},
+    "tags": {"type": "array", "minItems": 1, "maxItems": 3, "items": {"type": "string", "minLength": 2}}
+  }
+}`
+
+func TestSchema_Validate(t *testing.T) {
+	s, err := json.ParseSchema([]byte(userSchema))
+	assert.NoError(t, err)
+
+	for _, doc := range []string{
+		`{"id":1,"name":"alice","tags":["go"]}`,
+		`{"id":1.0,"name":"bob","email":"bob@example.com","role":null,"score":null,"tags":["go","js","py"]}`,
+		`{"id":10,"name":"carol","role":"admin","score":99.5,"tags":["go"],"other":true}`,
+	} {
+		assert.NoError(t, s.Validate([]byte(doc)), doc)
+	}
+
+	err = s.Validate([]byte(`{"id":0.5,"name":"Alice Liddell","email":"alice","role":"guest","score":120,"tags":["go","j",3,"x","y"]}`))
+	var ve *json.ValidationError
+	assert.True(t, errors.As(err, &ve))
+	assert.True(t, errors.Is(err, json.ErrValidation))
+	got := map[string]string{}
+	for _, v := range ve.Violations {
+		got[v.Pointer+" "+v.Keyword] = v.Message
+	}
+	assert.EqualValues(t, map[string]string{
+		"/email format":     "must be email",
+		"/id type":          "expected integer, got number",
+		"/name maxLength":   "length must be <= 8, got 13",
+		"/name pattern":     `must match "^[a-z]+$"`,
+		"/role enum":        `must be one of ["admin","user",null]`,
+		"/score maximum":    "must be <= 100, got 120",
+		"/tags maxItems":    "must have <= 3 items, got 5",
+		"/tags/1 minLength": "length must be >= 2, got 1",
+		"/tags/2 type":      "expected string, got number",
+		"/tags/3 minLength": "length must be >= 2, got 1",
+		"/tags/4 minLength": "length must be >= 2, got 1",
+	}, got)
+	// violations are in document order.
+	assert.EqualValues(t, "/email", ve.Violations[0].Pointer)
+	assert.True(t, strings.HasPrefix(err.Error(), "json: validation failed: /email: must be email; "))
+
+	err = s.Validate([]byte(`{"name":"a~b/c"}`))
+	assert.True(t, errors.As(err, &ve))
+	assert.EqualValues(t, []json.Violation{
+		{Pointer: "", Keyword: "required", Message: `missing property "id"`},
+		{Pointer: "", Keyword: "required", Message: `missing property "tags"`},
+		{Pointer: "/name", Keyword: "pattern", Message: `must match "^[a-z]+$"`},
+	}, ve.Violations)
+	assert.EqualValues(t, `json: validation failed: (root): missing property "id"; (root): missing property "tags"; /name: must match "^[a-z]+$"`, err.Error())
+
+	err = s.Validate([]byte(`[]`))
+	assert.True(t, errors.As(err, &ve))
+	assert.EqualValues(t, []json.Violation{{Keyword: "type", Message: "expected object, got array"}}, ve.Violations)
+
+	err = s.Validate([]byte(`{`))
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, json.ErrValidation))
+}
+
+func TestSchema_ValidateValue(t *testing.T) {
+	s := json.MustParseSchema([]byte(userSchema))
+	var doc map[string]interface{}
+	assert.NoError(t, stdjson.Unmarshal([]byte(`{"id":3,"name":"dave","tags":["go"]}`), &doc))
+	assert.NoError(t, s.ValidateValue(doc))
+
+	doc["id"] = -1
+	err := s.ValidateValue(doc)
+	var ve *json.ValidationError
+	assert.True(t, errors.As(err, &ve))
+	assert.EqualValues(t, []json.Violation{{Pointer: "/id", Keyword: "minimum", Message: "must be >= 1, got -1"}}, ve.Violations)
+}
+
+func TestSchema_Formats(t *testing.T) {
+	tests := []struct {
+		format    string
+		good, bad []string
+	}{
+		{"date-time", []string{"2024-01-02T03:04:05Z", "2024-01-02T03:04:05.123+09:00"}, []string{"2024-01-02", "2024-01-02 03:04:05"}},
+		{"date", []string{"2024-02-29"}, []string{"2023-02-29", "24-01-01"}},
+		{"time", []string{"03:04:05Z", "23:59:59.5+09:00"}, []string{"03:04", "25:00:00Z"}},
+		{"email", []string{"a@example.com"}, []string{"a", "A <a@example.com>"}},
+		{"hostname", []string{"example.com", "localhost"}, []string{"-a.com", "a..b", "a_b"}},
+		{"ipv4", []string{"192.0.2.1"}, []string{"::1", "256.0.0.1"}},
+		{"ipv6", []string{"::1", "2001:db8::1"}, []string{"192.0.2.1", "x::"}},
+		{"uri", []string{"https://example.com/a?b=c", "urn:isbn:0451450523"}, []string{"/relative", "example.com"}},
+		{"uuid", []string{"123e4567-e89b-12d3-a456-426614174000"}, []string{"123e4567e89b12d3a456426614174000"}},
+		{"unknown", []string{"anything"}, nil},
+	}
+	for _, tt := range tests {
+		s := &json.Schema{Format: tt.format}
+		for _, v := range tt.good {
+			assert.NoError(t, s.ValidateValue(v), tt.format+" "+v)
+		}
+		for _, v := range tt.bad {
+			assert.Error(t, s.ValidateValue(v), tt.format+" "+v)
+		}
+	}
+	// format applies only to strings.
+	assert.NoError(t, (&json.Schema{Format: "email"}).ValidateValue(1))
+}
+
+func TestParseSchema(t *testing.T) {
+	_, err := json.ParseSchema([]byte(`{"properties":{"a":{"items":{"pattern":"("}}}}`))
+	assert.True(t, errors.Is(err, json.ErrInvalidSchema))
+	assert.Contains(t, err.Error(), `"/properties/a/items"`)
+	_, err = json.ParseSchema([]byte(`{"type":1}`))
+	assert.True(t, errors.Is(err, json.ErrInvalidSchema))
+	assert.Panics(t, func() { json.MustParseSchema([]byte(`[`)) })
+
+	s, err := json.ParseSchema([]byte(`{"type":["string","null"],"x-unknown":1}`))
+	assert.NoError(t, err)
+	assert.EqualValues(t, json.SchemaTypes{"string", "null"}, s.Type)
+	assert.NoError(t, s.ValidateValue(nil))
+
+	b, err := stdjson.Marshal(&json.Schema{Type: json.SchemaTypes{"string"}})
+	assert.NoError(t, err)
+	assert.EqualValues(t, `{"type":"string"}`, string(b))
+}
+
+func TestValidateSchema(t *testing.T) {
+	s := json.MustParseSchema([]byte(userSchema))
+	type user struct {
+		ID   int64    `json:"id"`
+		Name string   `json:"name"`
+		Tags []string `json:"tags"`
+	}
+
+	u, err := json.Decode[user](strings.NewReader(`{"id":1,"name":"alice","tags":["go"]}`), json.ValidateSchema(s))
+	assert.NoError(t, err)
+	assert.EqualValues(t, user{ID: 1, Name: "alice", Tags: []string{"go"}}, u)
+
+	_, err = json.Decode[user](strings.NewReader(`{"id":1,"name":"alice"}`), json.ValidateSchema(s))
+	assert.True(t, errors.Is(err, json.ErrValidation))
+
+	// other options still work.
+	_, err = json.Decode[user](strings.NewReader(`{"id":1,"name":"alice","tags":["go"],"x":1}`),
+		json.ValidateSchema(s), json.DisallowUnknownFields())
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, json.ErrValidation))
+	_, err = json.Decode[user](strings.NewReader(`{"id":1,"name":"alice","tags":["go"]}`),
+		json.ValidateSchema(s), json.MaxBytes(10))
+	assert.True(t, errors.Is(err, json.ErrTooLarge))
+
+	// each line of NDJSON.
+	var bad []int
+	var names []string
+	for u, err := range json.ReadLines[user](strings.NewReader("{\"id\":1,\"name\":\"a\",\"tags\":[\"go\"]}\n{\"id\":2}\n"),
+		json.ValidateSchema(s), json.SkipBadLines(func(e *json.LineError) { bad = append(bad, e.Line) })) {
+		assert.NoError(t, err)
+		names = append(names, u.Name)
+	}
+	assert.EqualValues(t, []string{"a"}, names)
+	assert.EqualValues(t, []int{2}, bad)
+}
@@ -0,0 +1,241 @@
+package json
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SchemaTag is the struct tag that adds constraints to the schema of a field, separated by commas:
+//
+//	Name  string `json:"name" schema:"minLength=1,maxLength=64"`
+//	Role  string `json:"role,omitempty" schema:"enum=admin|user,required"`
+//	Mail  string `json:"mail" schema:"format=email,description=contact address"`
+//	Age   int    `json:"age" schema:"minimum=0,maximum=150,optional"`
+//
+// Keys are description, enum (separated by '|'), format, maximum, maxItems, maxLength, minimum, minItems, minLength,
+// pattern (can not have ','), required and optional. Constraints of slices other than minItems and maxItems apply to
+// their items. An unknown key or a malformed value makes SchemaOf fail with ErrInvalidSchema.
+const SchemaTag = "schema"
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	numberType     = reflect.TypeOf(json.Number(""))
+	rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+)
+
+// SchemaOf returns JSON Schema of the JSON form of given value's type, derived from json and schema tags of struct
+// fields. A field is required unless it has omitempty option or is a pointer. A pointer, a slice and a map are
+// nullable since nil is marshaled to null. time.Time is a date-time string and other types that implement
+// json.Marshaler accept any value.
+func SchemaOf(v interface{}) (*Schema, error) {
+	return rootSchema(reflect.TypeOf(v))
+}
+
+// MustSchemaOf is like SchemaOf but panics when the schema tag is invalid.
+func MustSchemaOf(v interface{}) *Schema {
+	s, err := SchemaOf(v)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// SchemaFor returns JSON Schema of type T. See SchemaOf.
+func SchemaFor[T any]() (*Schema, error) {
+	return rootSchema(reflect.TypeFor[T]())
+}
+
+// MustSchemaFor is like SchemaFor but panics when the schema tag is invalid.
+func MustSchemaFor[T any]() *Schema {
+	s, err := SchemaFor[T]()
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// rootSchema returns the schema of given type with $schema. Pointers at the root are not nullable.
+func rootSchema(t reflect.Type) (*Schema, error) {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	s, err := schemaOfType(t, map[reflect.Type]bool{})
+	if err != nil {
+		return nil, err
+	}
+	s.Schema = SchemaVersion
+	return s, nil
+}
+
+// schemaOfType returns the schema of given type. Types in visiting are recursive ones and accept any object.
+func schemaOfType(t reflect.Type, visiting map[reflect.Type]bool) (*Schema, error) {
+	if t == nil {
+		return &Schema{}, nil
+	}
+	if t.Kind() == reflect.Pointer {
+		s, err := schemaOfType(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		if len(s.Type) > 0 && !slices.Contains(s.Type, "null") {
+			s.Type = append(s.Type, "null")
+		}
+		return s, nil
+	}
+	switch t {
+	case timeType:
+		return &Schema{Type: SchemaTypes{"string"}, Format: "date-time"}, nil
+	case numberType:
+		return &Schema{Type: SchemaTypes{"number"}}, nil
+	case rawMessageType:
+		return &Schema{}, nil
+	}
+	if t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType) {
+		return &Schema{}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: SchemaTypes{"boolean"}}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &Schema{Type: SchemaTypes{"integer"}}, nil
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: SchemaTypes{"number"}}, nil
+	case reflect.String:
+		return &Schema{Type: SchemaTypes{"string"}}, nil
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: SchemaTypes{"string", "null"}}, nil // base64
+		}
+		items, err := schemaOfType(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		s := &Schema{Type: SchemaTypes{"array"}, Items: items}
+		if t.Kind() == reflect.Slice {
+			s.Type = append(s.Type, "null")
+		}
+		return s, nil
+	case reflect.Map:
+		return &Schema{Type: SchemaTypes{"object", "null"}}, nil
+	case reflect.Struct:
+		if visiting[t] {
+			return &Schema{Type: SchemaTypes{"object"}}, nil
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+		s := &Schema{Type: SchemaTypes{"object"}, Properties: map[string]*Schema{}}
+		for _, f := range structFields(t) {
+			sf := t.FieldByIndex(f.index)
+			fs, err := schemaOfType(sf.Type, visiting)
+			if err != nil {
+				return nil, err
+			}
+			required := !f.omitEmpty && sf.Type.Kind() != reflect.Pointer
+			if tag, ok := sf.Tag.Lookup(SchemaTag); ok {
+				if required, err = applySchemaTag(fs, tag, required); err != nil {
+					return nil, fmt.Errorf("%w: %s tag of %s.%s: %v", ErrInvalidSchema, SchemaTag, t, sf.Name, err)
+				}
+			}
+			if sf.Type.Kind() == reflect.Pointer && len(fs.Enum) > 0 {
+				fs.Enum = append(fs.Enum, nil)
+			}
+			s.Properties[f.name] = fs
+			if required {
+				s.Required = append(s.Required, f.name)
+			}
+		}
+		return s, nil
+	}
+	return &Schema{}, nil
+}
+
+// applySchemaTag sets constraints of given schema tag to the schema and returns whether the field is required.
+func applySchemaTag(s *Schema, tag string, required bool) (bool, error) {
+	for _, opt := range strings.Split(tag, ",") {
+		if opt == "" {
+			continue
+		}
+		key, value, hasValue := strings.Cut(opt, "=")
+		switch key {
+		case "required", "optional":
+			if hasValue {
+				return false, fmt.Errorf("%s takes no value", key)
+			}
+		default:
+			if !hasValue {
+				return false, fmt.Errorf("%s needs a value", key)
+			}
+		}
+		target := s
+		if s.Items != nil && key != "minItems" && key != "maxItems" && key != "description" {
+			target = s.Items
+		}
+		switch key {
+		case "required":
+			required = true
+		case "optional":
+			required = false
+		case "description":
+			target.Description = value
+		case "format":
+			target.Format = value
+		case "pattern":
+			if _, err := regexp.Compile(value); err != nil {
+				return false, err
+			}
+			target.Pattern = value
+		case "enum":
+			for _, e := range strings.Split(value, "|") {
+				target.Enum = append(target.Enum, enumValue(target, e))
+			}
+		case "minimum", "maximum":
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return false, fmt.Errorf("%s: %w", key, err)
+			}
+			if key == "minimum" {
+				target.Minimum = &f
+			} else {
+				target.Maximum = &f
+			}
+		case "minLength", "maxLength", "minItems", "maxItems":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return false, fmt.Errorf("%s: invalid count %q", key, value)
+			}
+			switch key {
+			case "minLength":
+				target.MinLength = &n
+			case "maxLength":
+				target.MaxLength = &n
+			case "minItems":
+				target.MinItems = &n
+			default:
+				target.MaxItems = &n
+			}
+		default:
+			return false, fmt.Errorf("unknown key %q", key)
+		}
+	}
+	return required, nil
+}
+
+// enumValue returns an enum value of given string for the schema: a string for a string schema, otherwise JSON
+// literal when it is valid.
+func enumValue(s *Schema, e string) interface{} {
+	if s.Type.match(e) {
+		return e
+	}
+	if v, err := decodeDocument([]byte(e)); err == nil {
+		return v
+	}
+	return e
+}
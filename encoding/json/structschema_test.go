@@ -0,0 +1,191 @@
+package json_test
+
+import (
+	stdjson "encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/marrbor/goutil/encoding/json"
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	schemaAddress struct {
+		City string `json:"city" schema:"minLength=1"`
+		Zip  string `json:"zip,omitempty" schema:"pattern=^[0-9]{3}-[0-9]{4}$"`
+	}
+
+	schemaNode struct {
+		Name     string        `json:"name"`
+		Children []*schemaNode `json:"children,omitempty"`
+	}
+
+	schemaUser struct {
+		schemaAddress
+		ID       int64              `json:"id" schema:"minimum=1"`
+		Name     string             `json:"name" schema:"minLength=1,maxLength=8,description=login name"`
+		Email    string             `json:"email,omitempty" schema:"format=email,required"`
+		Role     *string            `json:"role" schema:"enum=admin|user"`
+		Level    int                `json:"level" schema:"enum=1|2|3,optional"`
+		Score    float64            `json:"score" schema:"maximum=100"`
+		Tags     []string           `json:"tags" schema:"minItems=1,maxItems=3,minLength=2"`
+		Active   bool               `json:"active"`
+		Created  time.Time          `json:"created"`
+		Data     []byte             `json:"data,omitempty"`
+		Meta     map[string]string  `json:"meta,omitempty"`
+		Any      interface{}        `json:"any,omitempty"`
+		Raw      stdjson.RawMessage `json:"raw,omitempty"`
+		Tree     schemaNode         `json:"tree"`
+		internal string
+		Skip     string `json:"-"`
+	}
+)
+
+func TestSchemaOf(t *testing.T) {
+	s := json.MustSchemaFor[schemaUser]()
+	b, err := stdjson.Marshal(s)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+	  "$schema": "https://json-schema.org/draft/2020-12/schema",
+	  "type": "object",
+	  "properties": {
+	    "city": {"type": "string", "minLength": 1},
+	    "zip": {"type": "string", "pattern": "^[0-9]{3}-[0-9]{4}$"},
+	    "id": {"type": "integer", "minimum": 1},
+	    "name": {"type": "string", "description": "login name", "minLength": 1, "maxLength": 8},
+	    "email": {"type": "string", "format": "email"},
+	    "role": {"type": ["string", "null"], "enum": ["admin", "user", null]},
+	    "level": {"type": "integer", "enum": [1, 2, 3]},
+	    "score": {"type": "number", "maximum": 100},
+	    "tags": {"type": ["array", "null"], "items": {"type": "string", "minLength": 2}, "minItems": 1, "maxItems": 3},
+	    "active": {"type": "boolean"},
+	    "created": {"type": "string", "format": "date-time"},
+	    "data": {"type": ["string", "null"]},
+	    "meta": {"type": ["object", "null"]},
+	    "any": {},
+	    "raw": {},
+	    "tree": {
+	      "type": "object",
+	      "properties": {
+	        "name": {"type": "string"},
+	        "children": {"type": ["array", "null"], "items": {"type": ["object", "null"]}}
+	      },
+	      "required": ["name"]
+	    }
+	  },
+	  "required": ["city", "id", "name", "email", "score", "tags", "active", "created", "tree"]
+	}`, string(b))
+
+	// pointer is not nullable at the root.
+	assert.EqualValues(t, s, json.MustSchemaOf(&schemaUser{}))
+	assert.EqualValues(t, json.SchemaTypes{"integer"}, json.MustSchemaOf(1).Type)
+	assert.EqualValues(t, json.SchemaTypes{"array", "null"}, json.MustSchemaFor[[]schemaAddress]().Type)
+	assert.EqualValues(t, json.SchemaTypes{"array"}, json.MustSchemaFor[[2]int]().Type)
+	assert.EqualValues(t, json.SchemaTypes{"array", "null"}, json.MustSchemaFor[struct {
+		A *[]int `json:"a"`
+	}]().Properties["a"].Type)
+}
+
+func TestSchemaOf_ZeroValue(t *testing.T) {
+	type zero struct {
+		Tags    []string          `json:"tags"`
+		Data    []byte            `json:"data"`
+		Meta    map[string]int    `json:"meta"`
+		Ptr     *schemaNode       `json:"ptr"`
+		Nodes   []schemaNode      `json:"nodes"`
+		Fixed   [2]int            `json:"fixed"`
+		Nested  schemaNode        `json:"nested"`
+		Created time.Time         `json:"created"`
+		ByKey   map[string][]bool `json:"by_key"`
+	}
+	s := json.MustSchemaFor[zero]()
+
+	// own marshaled zero value is valid.
+	b, err := stdjson.Marshal(zero{})
+	assert.NoError(t, err)
+	assert.NoError(t, s.Validate(b), string(b))
+
+	b, err = stdjson.Marshal(zero{Tags: []string{"a"}, Meta: map[string]int{"a": 1}, ByKey: map[string][]bool{"x": nil}})
+	assert.NoError(t, err)
+	assert.NoError(t, s.Validate(b), string(b))
+}
+
+func TestSchemaOf_Validate(t *testing.T) {
+	s := json.MustSchemaFor[schemaUser]()
+	role := "admin"
+	u := schemaUser{
+		schemaAddress: schemaAddress{City: "Tokyo", Zip: "100-0001"},
+		ID:            1,
+		Name:          "alice",
+		Email:         "alice@example.com",
+		Role:          &role,
+		Level:         2,
+		Tags:          []string{"go"},
+		Created:       time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Tree:          schemaNode{Name: "root", Children: []*schemaNode{{Name: "leaf"}}},
+	}
+	b, err := stdjson.Marshal(u)
+	assert.NoError(t, err)
+	assert.NoError(t, s.Validate(b))
+
+	u.Role = nil
+	b, _ = stdjson.Marshal(u)
+	assert.NoError(t, s.Validate(b))
+
+	err = s.Validate([]byte(`{"city":"","zip":"1000001","id":0,"name":"alice","role":"guest","level":4,"score":1,` +
+		`"tags":[],"active":true,"created":"yesterday","tree":{"name":"x","children":[1]}}`))
+	var ve *json.ValidationError
+	assert.True(t, errors.As(err, &ve))
+	var got []string
+	for _, v := range ve.Violations {
+		got = append(got, v.Pointer+" "+v.Keyword)
+	}
+	assert.EqualValues(t, []string{
+		" required",
+		"/city minLength",
+		"/created format",
+		"/id minimum",
+		"/level enum",
+		"/role enum",
+		"/tags minItems",
+		"/tree/children/0 type",
+		"/zip pattern",
+	}, got)
+}
+
+func TestSchemaOf_InvalidTag(t *testing.T) {
+	tests := []interface{}{
+		struct {
+			A int `schema:"minimum=one"`
+		}{},
+		struct {
+			A string `schema:"maxLength=-1"`
+		}{},
+		struct {
+			A string `schema:"minLen=1"`
+		}{},
+		struct {
+			A string `schema:"pattern=("`
+		}{},
+		struct {
+			A string `schema:"required=false"`
+		}{},
+		struct {
+			A []struct {
+				B string `schema:"format"`
+			}
+		}{},
+	}
+	for _, v := range tests {
+		_, err := json.SchemaOf(v)
+		assert.True(t, errors.Is(err, json.ErrInvalidSchema), "%T", v)
+	}
+	assert.Panics(t, func() { json.MustSchemaOf(tests[0]) })
+
+	s, err := json.SchemaOf(struct {
+		A string `json:"a" schema:"minLength=1,"`
+	}{})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, *s.Properties["a"].MinLength)
+}
@@ -1,17 +1,19 @@
 module github.com/marrbor/goutil
 
-go 1.21
+go 1.24.0
 
 require (
 	github.com/google/uuid v1.1.1
 	github.com/stretchr/testify v1.4.0
-	golang.org/x/text v0.13.0
+	golang.org/x/crypto v0.45.0
+	golang.org/x/text v0.31.0
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.0 // indirect
 	github.com/kr/pretty v0.2.0 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/sys v0.38.0 // indirect
 	gopkg.in/check.v1 v1.0.0-20190902080502-41f04d3bba15 // indirect
 	gopkg.in/yaml.v2 v2.2.2 // indirect
 )
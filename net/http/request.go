@@ -5,23 +5,36 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"sync/atomic"
 
 	"github.com/marrbor/goutil/closer"
+	mj "github.com/marrbor/goutil/encoding/json"
 )
 
 // Receive request (for server side program)
 
+// requestBodyLimit holds max bytes of request body RequestJSONToParams reads.
+var requestBodyLimit atomic.Int64
+
+// RequestBodyLimit returns max bytes of request body RequestJSONToParams reads (default: 0, no limit).
+func RequestBodyLimit() int64 {
+	return requestBodyLimit.Load()
+}
+
+// SetRequestBodyLimit changes max bytes of request body RequestJSONToParams reads and returns the previous one.
+// 0 means no limit.
+func SetRequestBodyLimit(limit int64) int64 {
+	return requestBodyLimit.Swap(limit)
+}
+
 // RequestJSONToParams convert request JSON body to given structure.
-func RequestJSONToParams(r *http.Request, params interface{}) error {
+// Body longer than RequestBodyLimit is rejected with json.ErrTooLarge of goutil, and data after the JSON value
+// with json.ErrTrailingData of goutil. Additional options like json.DisallowUnknownFields can be given, and
+// json.ValidateSchema makes it return *json.ValidationError that BadRequestViolations can respond.
+func RequestJSONToParams(r *http.Request, params interface{}, opts ...mj.DecodeOption) error {
 	defer closer.Close(r.Body)
-	b, err := io.ReadAll(r.Body)
-	if err != nil {
-		return err
-	}
-	if err := json.Unmarshal(b, &params); err != nil {
-		return err
-	}
-	return nil
+	opts = append([]mj.DecodeOption{mj.MaxBytes(RequestBodyLimit())}, opts...)
+	return mj.DecodeReader(r.Body, params, opts...)
 }
 
 // Send request (for client side program)
@@ -3,10 +3,13 @@ package http_test
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	mj "github.com/marrbor/goutil/encoding/json"
 	mh "github.com/marrbor/goutil/net/http"
 	"github.com/stretchr/testify/assert"
 )
@@ -67,6 +70,81 @@ func TestRequestJSONToParams2(t *testing.T) {
 	assert.EqualValues(t, "400 Bad Request", r.Status)
 }
 
+func TestRequestJSONToParams3(t *testing.T) {
+	prev := mh.SetRequestBodyLimit(16)
+	defer mh.SetRequestBodyLimit(prev)
+	assert.EqualValues(t, 16, mh.RequestBodyLimit())
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var data testRequest
+		err := mh.RequestJSONToParams(r, &data, mj.DisallowUnknownFields())
+		if errors.Is(err, mj.ErrTooLarge) {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		if err != nil {
+			mh.BadRequest(w, err)
+			return
+		}
+		mh.ResponseOK(w)
+	})
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+
+	tests := map[string]int{
+		`{"id":1}`:                 http.StatusOK,
+		`{"id":1} {}`:              http.StatusBadRequest,
+		`{"x":1}`:                  http.StatusBadRequest,
+		`{"name":"too long body"}`: http.StatusRequestEntityTooLarge,
+	}
+	for body, want := range tests {
+		r, err := http.Post(ts.URL, "application/json", strings.NewReader(body))
+		assert.NoError(t, err)
+		assert.EqualValues(t, want, r.StatusCode, body)
+	}
+}
+
+func TestRequestJSONToParams4(t *testing.T) {
+	schema := mj.MustSchemaFor[testRequest]()
+	schema.Properties["id"].Minimum = new(float64)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var data testRequest
+		if err := mh.RequestJSONToParams(r, &data, mj.ValidateSchema(schema)); err != nil {
+			mh.BadRequestViolations(w, err)
+			return
+		}
+		mh.ResponseOK(w)
+	})
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+
+	r, err := http.Post(ts.URL, "application/json", strings.NewReader(`{"id":1,"name":"a","params":[]}`))
+	assert.NoError(t, err)
+	assert.EqualValues(t, http.StatusOK, r.StatusCode)
+
+	r, err = http.Post(ts.URL, "application/json", strings.NewReader(`{"id":-1,"params":["a",1]}`))
+	assert.NoError(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, r.StatusCode)
+	assert.EqualValues(t, "application/json", r.Header.Get("Content-Type"))
+	var body struct {
+		Error      string         `json:"error"`
+		Violations []mj.Violation `json:"violations"`
+	}
+	assert.NoError(t, mh.ResponseJSONToParams(r, &body))
+	assert.EqualValues(t, mj.ErrValidation.Error(), body.Error)
+	assert.EqualValues(t, []mj.Violation{
+		{Pointer: "", Keyword: "required", Message: `missing property "name"`},
+		{Pointer: "/id", Keyword: "minimum", Message: "must be >= 0, got -1"},
+		{Pointer: "/params/1", Keyword: "type", Message: "expected string, got number"},
+	}, body.Violations)
+
+	// not a validation error.
+	r, err = http.Post(ts.URL, "application/json", strings.NewReader(`{`))
+	assert.NoError(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, r.StatusCode)
+	assert.True(t, strings.HasPrefix(r.Header.Get("Content-Type"), "text/plain"))
+}
+
 func TestGenRequest(t *testing.T) {
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		assert.EqualValues(t, r.Method, http.MethodGet)
@@ -2,11 +2,13 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 
 	"github.com/marrbor/goutil/closer"
+	mj "github.com/marrbor/goutil/encoding/json"
 )
 
 // Send response (for server side program)
@@ -43,6 +45,28 @@ func Forbidden(w http.ResponseWriter, err error) {
 	errResponse(w, http.StatusForbidden, err)
 }
 
+// BadRequestViolations returns http 400. When err has *json.ValidationError of goutil, the body is JSON object that
+// has "error" message and "violations" with their JSON Pointer locations, otherwise it is same as BadRequest.
+func BadRequestViolations(w http.ResponseWriter, err error) {
+	var ve *mj.ValidationError
+	if !errors.As(err, &ve) {
+		BadRequest(w, err)
+		return
+	}
+	j, err := json.Marshal(struct {
+		Error      string         `json:"error"`
+		Violations []mj.Violation `json:"violations"`
+	}{mj.ErrValidation.Error(), ve.Violations})
+	if err != nil {
+		BadRequest(w, ve)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusBadRequest)
+	_, _ = w.Write(j)
+}
+
 // NotFound returns http 404
 func NotFound(w http.ResponseWriter, err error) {
 	errResponse(w, http.StatusNotFound, err)
@@ -98,17 +122,11 @@ func JSONResponse(w http.ResponseWriter, data interface{}) error {
 
 // //// Receive response (for client side program)
 
-// ResponseJSONToParams convert JSON body in response to given structure.
-func ResponseJSONToParams(r *http.Response, params interface{}) error {
-	defer closer.Close(r.Body)
-	b, err := io.ReadAll(r.Body)
-	if err != nil {
-		return err
-	}
-	if err := json.Unmarshal(b, params); err != nil {
-		return err
-	}
-	return nil
+// ResponseJSONToParams convert JSON body in response to given structure. Options like json.UseNumber of goutil
+// can be given.
+func ResponseJSONToParams(r *http.Response, params interface{}, opts ...mj.DecodeOption) error {
+	defer closer.DrainClose(r.Body)
+	return mj.DecodeReader(r.Body, params, opts...)
 }
 
 // ResponseToError convert status and body into an error if given function (default: IsSuccessful) returns false
@@ -120,7 +138,7 @@ func ResponseToError(r *http.Response, jf func(*http.Response) bool, ff func(int
 		return nil
 	}
 
-	defer closer.Close(r.Body)
+	defer closer.DrainClose(r.Body)
 	b, err := io.ReadAll(r.Body)
 	if err != nil {
 		return err
@@ -133,7 +151,7 @@ func ResponseToError(r *http.Response, jf func(*http.Response) bool, ff func(int
 		}
 	}
 	es := ff(r.StatusCode, r.Status, bs)
-	return fmt.Errorf(es)
+	return errors.New(es)
 }
 
 // //// Response class checker https://www.w3.org/Protocols/rfc2616/rfc2616-sec10.html
@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	mj "github.com/marrbor/goutil/encoding/json"
 	mh "github.com/marrbor/goutil/net/http"
 	"github.com/stretchr/testify/assert"
 )
@@ -37,6 +38,34 @@ func TestBadRequest(t *testing.T) {
 	assert.EqualValues(t, "bad request\n", string(body))
 }
 
+func TestBadRequestViolations(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/plain" {
+			mh.BadRequestViolations(w, fmt.Errorf("bad request"))
+			return
+		}
+		mh.BadRequestViolations(w, fmt.Errorf("wrapped: %w", &mj.ValidationError{Violations: []mj.Violation{
+			{Pointer: "/a", Keyword: "type", Message: "expected string, got number"},
+		}}))
+	})
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+
+	r, err := http.Get(ts.URL)
+	assert.NoError(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, r.StatusCode)
+	body, err := io.ReadAll(r.Body)
+	assert.NoError(t, err)
+	assert.EqualValues(t, `{"error":"json: validation failed","violations":[{"pointer":"/a","keyword":"type","message":"expected string, got number"}]}`, string(body))
+
+	r, err = http.Get(ts.URL + "/plain")
+	assert.NoError(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, r.StatusCode)
+	body, err = io.ReadAll(r.Body)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "bad request\n", string(body))
+}
+
 func TestForbidden(t *testing.T) {
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		mh.Forbidden(w, fmt.Errorf("forbidden"))
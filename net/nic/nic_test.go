@@ -26,7 +26,7 @@ func TestValidateMacAddress(t *testing.T) {
 	for _, card := range nics {
 		adr := card.HardwareAddr.String()
 		if 0 < len(adr) {
-			t.Logf(adr)
+			t.Log(adr)
 			assert.True(t, nic.ValidateMacAddress(adr))
 		}
 	}
@@ -4,6 +4,7 @@ import (
 	"encoding/csv"
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/marrbor/goutil/closer"
@@ -19,14 +20,7 @@ const (
 )
 
 func TestRuneWriter_Write(t *testing.T) {
-	const fileName = "out.csv"
-	_, err := os.Stat(fileName)
-	if err != nil {
-		// remove file
-		assert.NoError(t, os.Remove(fileName))
-	}
-
-	file, err := os.Create(fileName)
+	file, err := os.Create(filepath.Join(t.TempDir(), "out.csv"))
 	assert.NoError(t, err)
 	defer closer.Close(file)
 